@@ -1,43 +1,172 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
 	"time"
 
+	"google.golang.org/grpc"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
 	"github.com/dshaneg/elevator/internal/elevator/bank"
-	"github.com/dshaneg/elevator/internal/elevator/car"
-	"github.com/dshaneg/elevator/internal/passenger"
+	"github.com/dshaneg/elevator/internal/invariant"
+	"github.com/dshaneg/elevator/internal/metrics"
+	"github.com/dshaneg/elevator/internal/rpc"
+	"github.com/dshaneg/elevator/internal/rpc/simuvatorv1"
+	"github.com/dshaneg/elevator/internal/scenario"
+	"github.com/dshaneg/elevator/internal/server"
+	"github.com/dshaneg/elevator/internal/session"
+	"github.com/dshaneg/elevator/internal/sim"
+	"github.com/dshaneg/elevator/internal/tower"
+	"github.com/dshaneg/elevator/internal/trace"
 )
 
 func main() {
-	floorCount := 5
-	cars := []bank.Member{
-		car.NewCar(floorCount),
-		car.NewCar(floorCount),
-		car.NewCar(floorCount),
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(os.Args[2:])
+		return
 	}
 
-	b, err := bank.New(floorCount, cars)
+	hours := flag.Int("hours", 0, "run this many simulated hours as fast as possible and print a report; 0 runs in real time")
+	seed := flag.Uint64("seed", 1, "seed for the random numbers drawn by the simulation")
+	mtbf := flag.Duration("mtbf", 0, "mean time between failures of each car; 0 disables breakdowns")
+	mttr := flag.Duration("mttr", time.Hour, "mean time to repair a broken down car")
+	regen := flag.Bool("regen", false, "fit every car with a regenerative drive")
+	visitors := flag.Float64("visitors", 0.5, "scale of the arrival curve for visitors making one-off trips; 0 for none")
+	traffic := flag.String("traffic", "office", fmt.Sprintf("the visitors' traffic template, one of %v", arrivals.TemplateNames()))
+	scenarioFile := flag.String("scenario", "", "a JSON scenario file describing the visitors' traffic and the day's events, used instead of -traffic and -visitors")
+	traceFile := flag.String("trace", "", "a CSV or JSON log of calls from a real controller to replay as visitors' trips, on top of any other traffic")
+	energyWeight := flag.Float64("energy-weight", 0, "seconds of waiting charged per watt hour when dispatching; 0 ignores energy")
+	actors := flag.Bool("actors", false, "run every car as its own goroutine, taking commands from its bank over channels")
+	twins := flag.Bool("twins", false, "run the low- and high-rise banks as two shafts of twin cars each, in place of three conventional cars")
+	latency := flag.Int("latency", 0, "steps each message between a bank and its cars takes over the simulated field bus")
+	jitter := flag.Int("jitter", 0, "up to this many more steps a field bus message may take, at random")
+	loss := flag.Float64("loss", 0, "the chance each field bus message is lost, from 0 to 1")
+	check := flag.Bool("check", false, "check the safety and liveness invariants of every bank and car each step, reporting any violations")
+	metricsAddr := flag.String("metrics-addr", "", "in real time, the address to serve Prometheus metrics on at /metrics; empty for none")
+	flag.Parse()
+
+	cfg := tower.Config{
+		Seed:         *seed,
+		Regenerative: *regen,
+		EnergyWeight: *energyWeight,
+		Actors:       *actors,
+		Twins:        *twins,
+		Logger:       slog.Default(),
+	}
+	switch {
+	case *scenarioFile != "":
+		sc, err := scenario.LoadFile(*scenarioFile)
+		if err != nil {
+			panic(err)
+		}
+		cfg.Visitors = &sc
+	case *visitors > 0:
+		cfg.Visitors = &scenario.Scenario{Traffic: *traffic, Scale: *visitors}
+	}
+	if *traceFile != "" {
+		t, err := trace.LoadFile(*traceFile)
+		if err != nil {
+			panic(err)
+		}
+		cfg.Trace = t
+	}
+	if *latency > 0 || *jitter > 0 || *loss > 0 {
+		cfg.Network = &bank.Network{Latency: *latency, Jitter: *jitter, Loss: *loss}
+	}
+	if *check {
+		cfg.Checker = invariant.New()
+	}
+	if *mtbf > 0 {
+		cfg.Faults = &sim.FaultModel{MTBF: *mtbf, MTTR: *mttr}
+	}
+
+	s, err := tower.New(cfg)
 	if err != nil {
 		panic(err)
 	}
+	defer s.Close()
 
-	passengers := []*passenger.Passenger{
-		passenger.New(b, passenger.WithPrimaryFloor(3)),
+	if *hours > 0 {
+		s.Run(tower.Start.Add(time.Duration(*hours) * time.Hour))
+		if err := s.Report().Print(os.Stdout); err != nil {
+			panic(err)
+		}
+		return
 	}
-	runSim(passengers)
+	runSim(s, *metricsAddr)
 }
 
-func runSim(passengers []*passenger.Passenger) {
-	simTime := time.Date(0, 1, 1, 0, 0, 0, 0, time.Local)
+// serve runs the JSON REST API of package server, and the gRPC service of package rpc if given an address for it,
+// both sharing the same simulations, until the process is killed.
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "the address to serve the JSON REST API on")
+	grpcAddr := flags.String("grpc-addr", "", "the address to serve the gRPC service on; empty for none")
+	verbose := flags.Bool("v", false, "log the simulations' dispatch decisions and events")
+	flags.Parse(args)
+
+	var logger *slog.Logger
+	if *verbose {
+		logger = slog.Default()
+	}
+	sessions := session.NewRegistry()
+	defer sessions.Close()
+
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			panic(err)
+		}
+		gs := grpc.NewServer()
+		simuvatorv1.RegisterSimulatorServiceServer(gs, rpc.New(sessions, rpc.WithLogger(logger)))
+		slog.Info("serving gRPC", "addr", lis.Addr())
+		go func() {
+			if err := gs.Serve(lis); err != nil {
+				panic(err)
+			}
+		}()
+	}
+
+	slog.Info("serving", "addr", *addr)
+	if err := http.ListenAndServe(*addr, server.New(sessions, server.WithLogger(logger))); err != nil {
+		panic(err)
+	}
+}
+
+// runSim steps the Simulation once a second, serving its metrics at /metrics on metricsAddr if it is given.
+func runSim(s *sim.Simulation, metricsAddr string) {
+	var mu sync.Mutex
+	if metricsAddr != "" {
+		inspect := func(f func(*sim.Simulation)) {
+			mu.Lock()
+			defer mu.Unlock()
+			f(s)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler(inspect))
+		go func() {
+			if err := http.ListenAndServe(metricsAddr, mux); err != nil {
+				panic(err)
+			}
+		}()
+	}
+
+	plan := s.Building().Plan()
 
 	for range time.Tick(1 * time.Second) {
-		simTime = simTime.Add(1 * time.Minute)
-		fmt.Printf("%v Tick\n", simTime)
+		mu.Lock()
+		s.Step()
+		fmt.Printf("%v Tick\n", s.Now())
 
-		for _, p := range passengers {
-			p.Tick(simTime)
-			fmt.Printf("Passenger on floor %d\n", p.Floor())
+		for _, p := range s.Passengers() {
+			fmt.Printf("Passenger on floor %s\n", plan.Label(p.Floor()))
 		}
+		mu.Unlock()
 	}
 }
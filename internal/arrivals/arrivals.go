@@ -0,0 +1,182 @@
+// Package arrivals generates the trips people make through a Building as a Poisson process whose
+// rate follows the time of day, rather than having everyone arrive on the stroke of their shift.
+package arrivals
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Period is a stretch of the day with a steady arrival rate and mix of trips.
+//
+// Trips are incoming from the lobby to the other floors, outgoing from the other floors down to the lobby,
+// or interfloor between two of the other floors. The mix weights are relative, so {3, 1, 0} means three
+// incoming trips for every outgoing one.
+type Period struct {
+	Start      time.Duration // time of day the Period begins
+	Rate       float64       // trips per hour
+	Incoming   float64
+	Outgoing   float64
+	Interfloor float64
+}
+
+// Profile is a day of Periods in order of their start. Each Period lasts until the next one starts,
+// and the last one runs on past midnight until the first.
+type Profile []Period
+
+// OfficeDay is the classic traffic of an office building with about a hundred occupants: the morning up-peak,
+// two-way traffic over lunch and the evening down-peak, with a trickle of interfloor trips between.
+var OfficeDay = Profile{
+	{Start: 0, Rate: 1, Outgoing: 1},
+	{Start: 7 * time.Hour, Rate: 30, Incoming: 9, Outgoing: 0.5, Interfloor: 0.5},
+	{Start: 8 * time.Hour, Rate: 90, Incoming: 9, Outgoing: 0.5, Interfloor: 0.5}, // up-peak
+	{Start: 9 * time.Hour, Rate: 40, Incoming: 6, Outgoing: 1, Interfloor: 3},
+	{Start: 10 * time.Hour, Rate: 20, Incoming: 2, Outgoing: 2, Interfloor: 6},
+	{Start: 11*time.Hour + 45*time.Minute, Rate: 60, Incoming: 4, Outgoing: 4, Interfloor: 2}, // lunch
+	{Start: 13*time.Hour + 30*time.Minute, Rate: 20, Incoming: 2, Outgoing: 2, Interfloor: 6},
+	{Start: 16*time.Hour + 30*time.Minute, Rate: 50, Incoming: 0.5, Outgoing: 8, Interfloor: 1.5},
+	{Start: 17 * time.Hour, Rate: 90, Incoming: 0.5, Outgoing: 9, Interfloor: 0.5}, // down-peak
+	{Start: 18 * time.Hour, Rate: 20, Incoming: 0.5, Outgoing: 9, Interfloor: 0.5},
+	{Start: 19 * time.Hour, Rate: 3, Incoming: 1, Outgoing: 8, Interfloor: 1},
+}
+
+// Scale returns a copy of the Profile with every rate multiplied by f, such as to fit the curve
+// to a building's population.
+func (p Profile) Scale(f float64) Profile {
+	scaled := slices.Clone(p)
+	for i := range scaled {
+		scaled[i].Rate *= f
+	}
+	return scaled
+}
+
+// At returns the Period in effect at the given time.
+func (p Profile) At(t time.Time) Period {
+	y, m, d := t.Date()
+	sinceMidnight := t.Sub(time.Date(y, m, d, 0, 0, 0, 0, t.Location()))
+
+	i, found := slices.BinarySearchFunc(p, sinceMidnight, func(period Period, at time.Duration) int {
+		return int(period.Start - at)
+	})
+	if found {
+		return p[i]
+	}
+	if i == 0 {
+		return p[len(p)-1]
+	}
+	return p[i-1]
+}
+
+// peak returns the highest rate in the Profile.
+func (p Profile) peak() float64 {
+	peak := 0.0
+	for _, period := range p {
+		peak = max(peak, period.Rate)
+	}
+	return peak
+}
+
+// Trip is a person arriving at a landing wanting to go to another floor.
+type Trip struct {
+	At   time.Time
+	From int
+	To   int
+}
+
+// Source is anything that brings Trips through a Building over time, such as a Process or a replayed trace.
+type Source interface {
+	// Until returns the Trips that arrive up to and including the given time, in order, each only once.
+	Until(t time.Time) []Trip
+}
+
+// Process draws Trips through a Building from a Profile.
+type Process struct {
+	profile Profile
+	lobby   int
+	floors  []int // the floors other than the lobby that trips go to and from
+	rand    *rand.Rand
+	next    time.Time // the next candidate arrival
+}
+
+// New creates a Process drawing Trips from the Profile, starting at the given time, between the lobby
+// and the given floors. A floor given more than once is counted once.
+func New(profile Profile, start time.Time, lobby int, floors []int, r *rand.Rand) (*Process, error) {
+	if len(profile) == 0 {
+		return nil, errors.New("arrivals: the Profile has no Periods")
+	}
+	if !slices.IsSortedFunc(profile, func(a, b Period) int { return int(a.Start - b.Start) }) {
+		return nil, errors.New("arrivals: the Periods of the Profile are out of order")
+	}
+	floors = slices.DeleteFunc(slices.Clone(floors), func(floor int) bool { return floor == lobby })
+	slices.Sort(floors)
+	floors = slices.Compact(floors)
+	if len(floors) < 2 {
+		return nil, errors.New("arrivals: trips need at least two different floors besides the lobby")
+	}
+
+	p := Process{
+		profile: profile,
+		lobby:   lobby,
+		floors:  floors,
+		rand:    r,
+		next:    start,
+	}
+	p.advance()
+	return &p, nil
+}
+
+// Until returns the Trips that arrive up to and including the given time, in order.
+//
+// Arrivals are drawn by thinning: candidates come at the Profile's peak rate, and each is kept with the
+// chance that the rate at its time bears to the peak.
+func (p *Process) Until(t time.Time) []Trip {
+	trips := []Trip{}
+	if p.profile.peak() == 0 {
+		return trips
+	}
+
+	for !p.next.After(t) {
+		period := p.profile.At(p.next)
+		if p.rand.Float64()*p.profile.peak() < period.Rate {
+			if trip, ok := p.trip(p.next, period); ok {
+				trips = append(trips, trip)
+			}
+		}
+		p.advance()
+	}
+	return trips
+}
+
+// advance moves the next candidate arrival on by an exponentially distributed gap at the peak rate.
+func (p *Process) advance() {
+	peak := p.profile.peak()
+	if peak == 0 {
+		return
+	}
+	gap := p.rand.ExpFloat64() / peak * float64(time.Hour)
+	p.next = p.next.Add(time.Duration(gap))
+}
+
+// trip draws the kind of trip and its floors from the Period's mix.
+func (p *Process) trip(at time.Time, period Period) (Trip, bool) {
+	total := period.Incoming + period.Outgoing + period.Interfloor
+	if total == 0 {
+		return Trip{}, false
+	}
+
+	floor := func() int { return p.floors[p.rand.IntN(len(p.floors))] }
+	switch x := p.rand.Float64() * total; {
+	case x < period.Incoming:
+		return Trip{At: at, From: p.lobby, To: floor()}, true
+	case x < period.Incoming+period.Outgoing:
+		return Trip{At: at, From: floor(), To: p.lobby}, true
+	default:
+		from, to := floor(), floor()
+		for to == from {
+			to = floor()
+		}
+		return Trip{At: at, From: from, To: to}, true
+	}
+}
@@ -0,0 +1,153 @@
+package arrivals_test
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+)
+
+var tue = time.Date(2024, 11, 19, 0, 0, 0, 0, time.Local)
+
+func TestProfileAt(t *testing.T) {
+	profile := arrivals.Profile{
+		{Start: 7 * time.Hour, Rate: 10},
+		{Start: 12 * time.Hour, Rate: 20},
+		{Start: 18 * time.Hour, Rate: 5},
+	}
+
+	tests := []struct {
+		name     string
+		at       time.Duration
+		expected float64
+	}{
+		{name: "At the start of a period", at: 7 * time.Hour, expected: 10},
+		{name: "During a period", at: 15 * time.Hour, expected: 20},
+		{name: "The last period runs past midnight", at: 23 * time.Hour, expected: 5},
+		{name: "Until the first period starts", at: 3 * time.Hour, expected: 5},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, profile.At(tue.Add(tc.at)).Rate)
+		})
+	}
+}
+
+func TestScale(t *testing.T) {
+	scaled := arrivals.OfficeDay.Scale(2)
+	assert.Equal(t, 2*arrivals.OfficeDay[2].Rate, scaled[2].Rate)
+	assert.Equal(t, 90.0, arrivals.OfficeDay[2].Rate, "the original is untouched")
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile arrivals.Profile
+		floors  []int
+	}{
+		{name: "No periods", floors: []int{1, 2}},
+		{name: "Periods out of order", profile: arrivals.Profile{{Start: time.Hour}, {Start: 0}}, floors: []int{1, 2}},
+		{name: "One floor besides the lobby", profile: arrivals.OfficeDay, floors: []int{0, 1}},
+		{name: "One floor given twice", profile: arrivals.OfficeDay, floors: []int{3, 3}},
+		{name: "One floor given twice with the lobby", profile: arrivals.OfficeDay, floors: []int{0, 3, 3}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := arrivals.New(tc.profile, tue, 0, tc.floors, rand.New(rand.NewPCG(1, 2)))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestArrivalsFollowTheRate(t *testing.T) {
+	// quiet mornings and busy afternoons, over a hundred days
+	profile := arrivals.Profile{
+		{Start: 0, Rate: 10, Incoming: 1},
+		{Start: 12 * time.Hour, Rate: 50, Incoming: 1},
+	}
+	p, err := arrivals.New(profile, tue, 0, []int{1, 2, 3}, rand.New(rand.NewPCG(1, 2)))
+	assert.NoError(t, err)
+
+	mornings, afternoons := 0, 0
+	for _, trip := range p.Until(tue.AddDate(0, 0, 100)) {
+		if trip.At.Hour() < 12 {
+			mornings++
+		} else {
+			afternoons++
+		}
+	}
+
+	// 12000 and 60000 expected, give or take a few standard deviations
+	assert.InDelta(t, 12000, mornings, 400)
+	assert.InDelta(t, 60000, afternoons, 800)
+}
+
+func TestTripMix(t *testing.T) {
+	tests := []struct {
+		name   string
+		period arrivals.Period
+		check  func(t *testing.T, trip arrivals.Trip)
+	}{
+		{
+			name:   "Incoming trips start at the lobby",
+			period: arrivals.Period{Rate: 60, Incoming: 1},
+			check: func(t *testing.T, trip arrivals.Trip) {
+				assert.Equal(t, 0, trip.From)
+				assert.Contains(t, []int{1, 2, 3}, trip.To)
+			},
+		},
+		{
+			name:   "Outgoing trips end at the lobby",
+			period: arrivals.Period{Rate: 60, Outgoing: 1},
+			check: func(t *testing.T, trip arrivals.Trip) {
+				assert.Contains(t, []int{1, 2, 3}, trip.From)
+				assert.Equal(t, 0, trip.To)
+			},
+		},
+		{
+			name:   "Interfloor trips stay off the lobby",
+			period: arrivals.Period{Rate: 60, Interfloor: 1},
+			check: func(t *testing.T, trip arrivals.Trip) {
+				assert.Contains(t, []int{1, 2, 3}, trip.From)
+				assert.Contains(t, []int{1, 2, 3}, trip.To)
+				assert.NotEqual(t, trip.From, trip.To)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := arrivals.New(arrivals.Profile{tc.period}, tue, 0, []int{0, 1, 2, 3}, rand.New(rand.NewPCG(1, 2)))
+			assert.NoError(t, err)
+
+			trips := p.Until(tue.Add(2 * time.Hour))
+			assert.NotEmpty(t, trips)
+			for _, trip := range trips {
+				tc.check(t, trip)
+			}
+		})
+	}
+}
+
+func TestUntilIsIncremental(t *testing.T) {
+	newProcess := func() *arrivals.Process {
+		p, err := arrivals.New(arrivals.OfficeDay, tue, 0, []int{1, 2, 3, 4}, rand.New(rand.NewPCG(7, 7)))
+		assert.NoError(t, err)
+		return p
+	}
+
+	all := newProcess().Until(tue.Add(24 * time.Hour))
+
+	stepped := []arrivals.Trip{}
+	p := newProcess()
+	for at := tue; at.Before(tue.Add(24 * time.Hour)); {
+		at = at.Add(time.Minute)
+		stepped = append(stepped, p.Until(at)...)
+	}
+	assert.Equal(t, all, stepped)
+}
@@ -0,0 +1,114 @@
+package arrivals
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Hotel is the traffic of a hotel with about a hundred guests: checkouts through the morning,
+// guests heading to breakfast and dinner, and check-ins through the afternoon and evening.
+var Hotel = Profile{
+	{Start: 0, Rate: 6, Incoming: 7, Outgoing: 1, Interfloor: 2},
+	{Start: 2 * time.Hour, Rate: 1, Incoming: 1, Outgoing: 1},
+	{Start: 6 * time.Hour, Rate: 20, Incoming: 1, Outgoing: 6, Interfloor: 3},
+	{Start: 7 * time.Hour, Rate: 50, Incoming: 2, Outgoing: 6, Interfloor: 2}, // breakfast
+	{Start: 9 * time.Hour, Rate: 40, Incoming: 2, Outgoing: 7, Interfloor: 1}, // checkout
+	{Start: 12 * time.Hour, Rate: 20, Incoming: 4, Outgoing: 4, Interfloor: 2},
+	{Start: 15 * time.Hour, Rate: 40, Incoming: 7, Outgoing: 2, Interfloor: 1}, // check-in
+	{Start: 18 * time.Hour, Rate: 45, Incoming: 4, Outgoing: 4, Interfloor: 2}, // dinner
+	{Start: 21 * time.Hour, Rate: 25, Incoming: 7, Outgoing: 1, Interfloor: 2},
+}
+
+// Residential is the traffic of an apartment building with about a hundred residents: people leaving for work
+// and school in the morning, coming home in the evening, and a steady trickle between.
+var Residential = Profile{
+	{Start: 0, Rate: 2, Incoming: 8, Outgoing: 2},
+	{Start: 6 * time.Hour, Rate: 15, Incoming: 1, Outgoing: 9},
+	{Start: 7 * time.Hour, Rate: 45, Incoming: 0.5, Outgoing: 9, Interfloor: 0.5}, // down-peak
+	{Start: 9 * time.Hour, Rate: 12, Incoming: 5, Outgoing: 5},
+	{Start: 16 * time.Hour, Rate: 25, Incoming: 8, Outgoing: 2},
+	{Start: 17 * time.Hour, Rate: 40, Incoming: 8.5, Outgoing: 1, Interfloor: 0.5}, // up-peak
+	{Start: 19 * time.Hour, Rate: 20, Incoming: 5, Outgoing: 4, Interfloor: 1},
+	{Start: 22 * time.Hour, Rate: 6, Incoming: 7, Outgoing: 2, Interfloor: 1},
+}
+
+// Hospital is the traffic of a hospital with about a hundred staff: busy around the clock, with peaks
+// at the shift changes and visiting hours, and heavy interfloor traffic between wards and departments.
+var Hospital = Profile{
+	{Start: 0, Rate: 15, Incoming: 2, Outgoing: 2, Interfloor: 6},
+	{Start: 6*time.Hour + 30*time.Minute, Rate: 70, Incoming: 4, Outgoing: 4, Interfloor: 2}, // shift change
+	{Start: 7*time.Hour + 30*time.Minute, Rate: 40, Incoming: 3, Outgoing: 1, Interfloor: 6},
+	{Start: 11 * time.Hour, Rate: 55, Incoming: 5, Outgoing: 2, Interfloor: 3},                // visiting hours
+	{Start: 14*time.Hour + 30*time.Minute, Rate: 70, Incoming: 4, Outgoing: 4, Interfloor: 2}, // shift change
+	{Start: 15*time.Hour + 30*time.Minute, Rate: 40, Incoming: 2, Outgoing: 3, Interfloor: 5},
+	{Start: 18 * time.Hour, Rate: 50, Incoming: 4, Outgoing: 4, Interfloor: 2}, // evening visits
+	{Start: 21 * time.Hour, Rate: 25, Incoming: 2, Outgoing: 5, Interfloor: 3},
+	{Start: 22*time.Hour + 30*time.Minute, Rate: 50, Incoming: 4, Outgoing: 4, Interfloor: 2}, // shift change
+	{Start: 23*time.Hour + 30*time.Minute, Rate: 15, Incoming: 2, Outgoing: 2, Interfloor: 6},
+}
+
+// MixedUse is a tower of offices over apartments with a hotel on top, each bringing half the traffic
+// it would on its own.
+var MixedUse = Combine(OfficeDay.Scale(0.5), Residential.Scale(0.5), Hotel.Scale(0.5))
+
+// templates are the Profiles that can be chosen by name.
+var templates = map[string]Profile{
+	"office":      OfficeDay,
+	"hotel":       Hotel,
+	"residential": Residential,
+	"hospital":    Hospital,
+	"mixed-use":   MixedUse,
+}
+
+// Template returns the built-in Profile with the given name.
+func Template(name string) (Profile, error) {
+	p, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("arrivals: no traffic template named %q; choose from %v", name, TemplateNames())
+	}
+	return p, nil
+}
+
+// TemplateNames returns the names of the built-in Profiles in alphabetical order.
+func TemplateNames() []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Combine returns the traffic of several Profiles together: its rate at any time is the sum of theirs,
+// and its mix of trips is theirs weighted by their rates.
+func Combine(profiles ...Profile) Profile {
+	starts := []time.Duration{}
+	for _, p := range profiles {
+		for _, period := range p {
+			starts = append(starts, period.Start)
+		}
+	}
+	slices.Sort(starts)
+	starts = slices.Compact(starts)
+
+	combined := Profile{}
+	day := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, start := range starts {
+		sum := Period{Start: start}
+		for _, p := range profiles {
+			period := p.At(day.Add(start))
+			sum.Rate += period.Rate
+
+			total := period.Incoming + period.Outgoing + period.Interfloor
+			if total == 0 {
+				continue
+			}
+			sum.Incoming += period.Rate * period.Incoming / total
+			sum.Outgoing += period.Rate * period.Outgoing / total
+			sum.Interfloor += period.Rate * period.Interfloor / total
+		}
+		combined = append(combined, sum)
+	}
+	return combined
+}
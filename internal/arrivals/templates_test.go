@@ -0,0 +1,51 @@
+package arrivals_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+)
+
+func TestTemplates(t *testing.T) {
+	assert.Equal(t, []string{"hospital", "hotel", "mixed-use", "office", "residential"}, arrivals.TemplateNames())
+
+	for _, name := range arrivals.TemplateNames() {
+		t.Run(name, func(t *testing.T) {
+			profile, err := arrivals.Template(name)
+			assert.NoError(t, err)
+
+			// every template covers the whole day and generates trips
+			assert.Equal(t, time.Duration(0), profile[0].Start)
+			p, err := arrivals.New(profile, tue, 0, []int{1, 2, 3}, rand.New(rand.NewPCG(1, 2)))
+			assert.NoError(t, err)
+			assert.NotEmpty(t, p.Until(tue.Add(24*time.Hour)))
+		})
+	}
+
+	_, err := arrivals.Template("stadium")
+	assert.Error(t, err)
+}
+
+func TestCombine(t *testing.T) {
+	a := arrivals.Profile{
+		{Start: 0, Rate: 10, Incoming: 1},
+		{Start: 12 * time.Hour, Rate: 30, Outgoing: 1},
+	}
+	b := arrivals.Profile{
+		{Start: 0, Rate: 20, Interfloor: 1},
+		{Start: 8 * time.Hour, Rate: 40, Incoming: 1, Outgoing: 1},
+	}
+
+	expected := arrivals.Profile{
+		{Start: 0, Rate: 30, Incoming: 10, Interfloor: 20},
+		{Start: 8 * time.Hour, Rate: 50, Incoming: 30, Outgoing: 20},
+		{Start: 12 * time.Hour, Rate: 70, Incoming: 20, Outgoing: 50},
+	}
+	assert.Equal(t, expected, arrivals.Combine(a, b))
+	assert.True(t, slices.IsSortedFunc(arrivals.MixedUse, func(a, b arrivals.Period) int { return int(a.Start - b.Start) }))
+}
@@ -0,0 +1,243 @@
+package building
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/floorplan"
+)
+
+// Zone is a Bank along with the floors its cars stop at.
+//
+// A shuttle bank running express between the ground floor and a sky lobby would serve
+// only those two floors, while a local bank serves every floor in its range.
+//
+// A Zone of double-deck cars has a lobby on two levels, one for each deck. Passengers leaving the lobby
+// board from the level whose deck serves their destination, and those riding to it get off at the level
+// their deck stops at, so the decks stay in step; the levels are joined by stairs or escalators.
+type Zone struct {
+	Name    string
+	Bank    *bank.Bank
+	Floors  []int // the floors served by the Bank, in ascending order
+	Lobbies []int // the two adjacent levels of a double-deck lobby, if the Zone has one
+}
+
+// Serves reports whether the Zone's cars stop at the given floor.
+func (z *Zone) Serves(floor int) bool {
+	_, found := slices.BinarySearch(z.Floors, floor)
+	return found
+}
+
+// lobbyFor returns the lobby level in step with the floor, served by the same deck, if the Zone has a lobby
+// on two levels and the floor is one of them; otherwise it returns the floor itself.
+func (z *Zone) lobbyFor(lobby, floor int) int {
+	if !slices.Contains(z.Lobbies, lobby) {
+		return lobby
+	}
+	for _, level := range z.Lobbies {
+		if (level-floor)%2 == 0 {
+			return level
+		}
+	}
+	return lobby
+}
+
+// Building represents a collection of elevator banks that serve the floors of a single structure.
+//
+// Floors served by more than one Zone are transfer floors, where passengers can change banks.
+type Building struct {
+	plan    *floorplan.Plan
+	zones   []*Zone
+	waiting map[Landing]int // passengers waiting at each landing
+}
+
+// Leg is a single ride on one Bank as part of a trip through the Building.
+type Leg struct {
+	Zone *Zone
+	From int
+	To   int
+}
+
+// Span returns the floors from low to high, inclusive.
+func Span(low, high int) []int {
+	floors := make([]int, 0, high-low+1)
+	for floor := low; floor <= high; floor++ {
+		floors = append(floors, floor)
+	}
+	return floors
+}
+
+// New creates a new Building with the given floor plan and zones.
+func New(plan *floorplan.Plan, zones ...Zone) (*Building, error) {
+	if len(zones) == 0 {
+		return nil, errors.New("building: New requires at least one Zone")
+	}
+
+	b := Building{
+		plan:    plan,
+		waiting: map[Landing]int{},
+	}
+	numFloors := plan.Len()
+
+	names := map[string]bool{}
+	for _, z := range zones {
+		if z.Bank == nil {
+			return nil, fmt.Errorf("building: zone %q has no Bank", z.Name)
+		}
+		if names[z.Name] {
+			return nil, fmt.Errorf("building: zone %q is defined more than once", z.Name)
+		}
+		if n := z.Bank.NumFloors(); n != numFloors {
+			return nil, fmt.Errorf("building: zone %q has a Bank of %d floors in a building of %d", z.Name, n, numFloors)
+		}
+		names[z.Name] = true
+
+		floors := slices.Clone(z.Floors)
+		slices.Sort(floors)
+		floors = slices.Compact(floors)
+		if len(floors) < 2 {
+			return nil, fmt.Errorf("building: zone %q must serve at least two floors", z.Name)
+		}
+		if floors[0] < 0 || floors[len(floors)-1] >= numFloors {
+			return nil, fmt.Errorf("building: zone %q serves floors outside %s..%s", z.Name, plan.Label(0), plan.Label(numFloors-1))
+		}
+
+		if len(z.Lobbies) > 0 {
+			lobbies := slices.Clone(z.Lobbies)
+			slices.Sort(lobbies)
+			if len(lobbies) != 2 || lobbies[1] != lobbies[0]+1 || !slices.Contains(floors, lobbies[0]) || !slices.Contains(floors, lobbies[1]) {
+				return nil, fmt.Errorf("building: zone %q must have its lobby on two adjacent floors it serves", z.Name)
+			}
+			z.Lobbies = lobbies
+		}
+
+		z.Floors = floors
+		b.zones = append(b.zones, &z)
+	}
+
+	return &b, nil
+}
+
+// Plan returns the floor plan of the Building.
+func (b *Building) Plan() *floorplan.Plan {
+	return b.plan
+}
+
+// NumFloors returns the number of floors in the Building.
+func (b *Building) NumFloors() int {
+	return b.plan.Len()
+}
+
+// Zones returns the zones of the Building in the order they were given to New.
+func (b *Building) Zones() []*Zone {
+	return b.zones
+}
+
+// Zone returns the Zone with the given name.
+func (b *Building) Zone(name string) (*Zone, bool) {
+	for _, z := range b.zones {
+		if z.Name == name {
+			return z, true
+		}
+	}
+	return nil, false
+}
+
+// TransferFloors returns the floors served by more than one Zone, in ascending order.
+func (b *Building) TransferFloors() []int {
+	transfers := []int{}
+	for floor := range b.NumFloors() {
+		if len(b.zonesServing(floor)) > 1 {
+			transfers = append(transfers, floor)
+		}
+	}
+	return transfers
+}
+
+// Route plans a trip from one floor to another with the fewest rides,
+// changing banks only at transfer floors. A ride to or from a Zone's lobby
+// starts or ends at the level in step with the other end; see [Zone].
+func (b *Building) Route(from, to int) ([]Leg, error) {
+	if from == to {
+		return []Leg{}, nil
+	}
+
+	// breadth-first search over the floors a passenger can ride between:
+	// the origin, the destination and every transfer floor.
+	stops := append([]int{from, to}, b.TransferFloors()...)
+	slices.Sort(stops)
+	stops = slices.Compact(stops)
+
+	previous := map[int]Leg{from: {}}
+	queue := []int{from}
+	for len(queue) > 0 && !hasKey(previous, to) {
+		floor := queue[0]
+		queue = queue[1:]
+
+		for _, z := range b.zonesServing(floor) {
+			for _, next := range stops {
+				if hasKey(previous, next) || !z.Serves(next) {
+					continue
+				}
+				previous[next] = Leg{Zone: z, From: floor, To: next}
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if !hasKey(previous, to) {
+		return nil, fmt.Errorf("building: no route from floor %s to floor %s", b.plan.Label(from), b.plan.Label(to))
+	}
+
+	legs := []Leg{}
+	for floor := to; floor != from; floor = previous[floor].From {
+		legs = append(legs, previous[floor])
+	}
+	slices.Reverse(legs)
+
+	for i, leg := range legs {
+		if from := leg.Zone.lobbyFor(leg.From, leg.To); from != leg.To {
+			legs[i].From = from
+		}
+		if to := leg.Zone.lobbyFor(leg.To, legs[i].From); to != legs[i].From {
+			legs[i].To = to
+		}
+	}
+
+	return legs, nil
+}
+
+// InFireService reports whether any bank in the Building has been recalled for fire service,
+// in which case everyone in the Building should leave by the stairs.
+func (b *Building) InFireService() bool {
+	for _, z := range b.zones {
+		if z.Bank.Mode() != bank.Normal {
+			return true
+		}
+	}
+	return false
+}
+
+// Tick advances every bank in the Building by one step.
+func (b *Building) Tick() {
+	for _, z := range b.zones {
+		z.Bank.Tick()
+	}
+}
+
+func (b *Building) zonesServing(floor int) []*Zone {
+	zones := []*Zone{}
+	for _, z := range b.zones {
+		if z.Serves(floor) {
+			zones = append(zones, z)
+		}
+	}
+	return zones
+}
+
+func hasKey[K comparable, V any](m map[K]V, key K) bool {
+	_, ok := m[key]
+	return ok
+}
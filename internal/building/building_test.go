@@ -0,0 +1,213 @@
+package building_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/floorplan"
+)
+
+func newBank(t *testing.T, numFloors int) *bank.Bank {
+	c, err := car.NewCar(numFloors)
+	assert.NoError(t, err)
+	b, err := bank.New(numFloors, []bank.Member{c})
+	assert.NoError(t, err)
+	return b
+}
+
+// newTower builds a 12 floor tower with a sky lobby on 6:
+// low-rise serves 0..5, the shuttle runs 0 <-> 6, and high-rise serves 6..11.
+func newTower(t *testing.T) *building.Building {
+	b, err := building.New(floorplan.Numbered(12),
+		building.Zone{Name: "low-rise", Bank: newBank(t, 12), Floors: building.Span(0, 5)},
+		building.Zone{Name: "shuttle", Bank: newBank(t, 12), Floors: []int{6, 0}},
+		building.Zone{Name: "high-rise", Bank: newBank(t, 12), Floors: building.Span(6, 11)},
+	)
+	assert.NoError(t, err)
+	return b
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		zones func(t *testing.T) []building.Zone
+	}{
+		{
+			name:  "Returns an error with no zones",
+			zones: func(t *testing.T) []building.Zone { return nil },
+		},
+		{
+			name: "Returns an error when a zone has no bank",
+			zones: func(t *testing.T) []building.Zone {
+				return []building.Zone{{Name: "a", Floors: building.Span(0, 4)}}
+			},
+		},
+		{
+			name: "Returns an error when a zone serves a single floor",
+			zones: func(t *testing.T) []building.Zone {
+				return []building.Zone{{Name: "a", Bank: newBank(t, 5), Floors: []int{2}}}
+			},
+		},
+		{
+			name: "Returns an error when a zone serves a floor outside the building",
+			zones: func(t *testing.T) []building.Zone {
+				return []building.Zone{{Name: "a", Bank: newBank(t, 5), Floors: building.Span(0, 5)}}
+			},
+		},
+		{
+			name: "Returns an error when a zone's bank serves a different number of floors",
+			zones: func(t *testing.T) []building.Zone {
+				return []building.Zone{{Name: "a", Bank: newBank(t, 4), Floors: building.Span(0, 3)}}
+			},
+		},
+		{
+			name: "Returns an error when a lobby is on one level",
+			zones: func(t *testing.T) []building.Zone {
+				return []building.Zone{{Name: "a", Bank: newBank(t, 5), Floors: building.Span(0, 4), Lobbies: []int{0}}}
+			},
+		},
+		{
+			name: "Returns an error when lobby levels are not adjacent",
+			zones: func(t *testing.T) []building.Zone {
+				return []building.Zone{{Name: "a", Bank: newBank(t, 5), Floors: building.Span(0, 4), Lobbies: []int{0, 2}}}
+			},
+		},
+		{
+			name: "Returns an error when a lobby level is not served",
+			zones: func(t *testing.T) []building.Zone {
+				return []building.Zone{{Name: "a", Bank: newBank(t, 5), Floors: []int{0, 2, 3, 4}, Lobbies: []int{1, 0}}}
+			},
+		},
+		{
+			name: "Returns an error when zone names repeat",
+			zones: func(t *testing.T) []building.Zone {
+				return []building.Zone{
+					{Name: "a", Bank: newBank(t, 5), Floors: building.Span(0, 2)},
+					{Name: "a", Bank: newBank(t, 5), Floors: building.Span(2, 4)},
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := building.New(floorplan.Numbered(5), tc.zones(t)...)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTransferFloors(t *testing.T) {
+	b := newTower(t)
+	assert.Equal(t, []int{0, 6}, b.TransferFloors())
+}
+
+func TestRoute(t *testing.T) {
+	type leg struct {
+		zone     string
+		from, to int
+	}
+	tests := []struct {
+		name     string
+		from, to int
+		expected []leg
+	}{
+		{
+			name:     "Same floor needs no rides",
+			from:     3,
+			to:       3,
+			expected: []leg{},
+		},
+		{
+			name:     "Within the low-rise zone takes one ride",
+			from:     0,
+			to:       4,
+			expected: []leg{{"low-rise", 0, 4}},
+		},
+		{
+			name:     "Ground to sky lobby takes the shuttle",
+			from:     0,
+			to:       6,
+			expected: []leg{{"shuttle", 0, 6}},
+		},
+		{
+			name:     "Ground to high-rise transfers at the sky lobby",
+			from:     0,
+			to:       10,
+			expected: []leg{{"shuttle", 0, 6}, {"high-rise", 6, 10}},
+		},
+		{
+			name:     "High-rise to low-rise transfers at the sky lobby and the ground floor",
+			from:     9,
+			to:       3,
+			expected: []leg{{"high-rise", 9, 6}, {"shuttle", 6, 0}, {"low-rise", 0, 3}},
+		},
+	}
+
+	b := newTower(t)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			legs, err := b.Route(tc.from, tc.to)
+			assert.NoError(t, err)
+
+			got := []leg{}
+			for _, l := range legs {
+				got = append(got, leg{l.Zone.Name, l.From, l.To})
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestRouteThroughDoubleDeckLobby(t *testing.T) {
+	type leg struct{ from, to int }
+	tests := []struct {
+		name     string
+		from, to int
+		expected leg
+	}{
+		{name: "Boards the lower deck for an even floor", from: 0, to: 6, expected: leg{0, 6}},
+		{name: "Boards the upper deck for an odd floor", from: 0, to: 7, expected: leg{1, 7}},
+		{name: "Gets off the upper deck from an odd floor", from: 7, to: 0, expected: leg{7, 1}},
+		{name: "Gets off the lower deck from an even floor", from: 6, to: 1, expected: leg{6, 0}},
+		{name: "Rides between the levels", from: 0, to: 1, expected: leg{0, 1}},
+	}
+
+	b, err := building.New(floorplan.Numbered(10),
+		building.Zone{Name: "double-deck", Bank: newBank(t, 10), Floors: building.Span(0, 9), Lobbies: []int{1, 0}},
+	)
+	assert.NoError(t, err)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			legs, err := b.Route(tc.from, tc.to)
+			assert.NoError(t, err)
+			assert.Len(t, legs, 1)
+			assert.Equal(t, tc.expected, leg{legs[0].From, legs[0].To})
+		})
+	}
+}
+
+func TestRouteError(t *testing.T) {
+	b, err := building.New(floorplan.Numbered(10),
+		building.Zone{Name: "low", Bank: newBank(t, 10), Floors: building.Span(0, 4)},
+		building.Zone{Name: "high", Bank: newBank(t, 10), Floors: building.Span(5, 9)},
+	)
+	assert.NoError(t, err)
+
+	_, err = b.Route(0, 7)
+	assert.Error(t, err)
+}
+
+func TestLandings(t *testing.T) {
+	b := newTower(t)
+	shuttle, _ := b.Zone("shuttle")
+
+	assert.Equal(t, []building.Landing{
+		{Zone: shuttle, Floor: 0, Direction: car.Up},
+		{Zone: shuttle, Floor: 6, Direction: car.Down},
+	}, shuttle.Landings())
+}
@@ -0,0 +1,45 @@
+package building
+
+import "github.com/dshaneg/elevator/internal/elevator/car"
+
+// Landing is the hall outside one Zone's cars on a floor, where passengers wait to go in one direction.
+type Landing struct {
+	Zone      *Zone
+	Floor     int
+	Direction car.Direction
+}
+
+// Landings returns every Landing of the Zone from the bottom up, leaving out going down from its lowest floor
+// and going up from its highest.
+func (z *Zone) Landings() []Landing {
+	landings := []Landing{}
+	for i, floor := range z.Floors {
+		if i > 0 {
+			landings = append(landings, Landing{z, floor, car.Down})
+		}
+		if i < len(z.Floors)-1 {
+			landings = append(landings, Landing{z, floor, car.Up})
+		}
+	}
+	return landings
+}
+
+// Waiting returns how many passengers are waiting at the Landing.
+func (b *Building) Waiting(l Landing) int {
+	return b.waiting[l]
+}
+
+// Join adds a passenger to those waiting at the Landing.
+func (b *Building) Join(l Landing) {
+	b.waiting[l]++
+}
+
+// Leave takes a passenger away from the Landing, returning how many are still waiting there.
+func (b *Building) Leave(l Landing) int {
+	if b.waiting[l] <= 1 {
+		delete(b.waiting, l)
+		return 0
+	}
+	b.waiting[l]--
+	return b.waiting[l]
+}
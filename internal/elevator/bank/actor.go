@@ -0,0 +1,235 @@
+package bank
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/elevator/car"
+)
+
+// CarState is a snapshot of a car, as published by an Actor.
+type CarState struct {
+	Floor     int
+	Direction car.Direction
+	Status    car.Status
+	Door      car.Door
+	Mode      car.Mode
+	Fault     car.Fault
+	Load      int
+	Capacity  int
+	Calls     []int
+	Energy    car.Meter
+}
+
+// snapshot reads the state of the car.
+func snapshot(c Member) CarState {
+	return CarState{
+		Floor:     c.Floor(),
+		Direction: c.Direction(),
+		Status:    c.Status(),
+		Door:      c.Door(),
+		Mode:      c.Mode(),
+		Fault:     c.Fault(),
+		Load:      c.Load(),
+		Capacity:  c.Capacity(),
+		Calls:     c.Calls(),
+		Energy:    c.Energy(),
+	}
+}
+
+// Actor runs a car as its own goroutine, the car controller, which alone touches the car. Everything
+// that changes the car is sent to the controller as a command, and the controller publishes the car's
+// state after each one. Questions about the state are answered from the latest publication, without
+// a round trip; those that need the car to work something out, such as [Actor.Score], are commands too.
+//
+// Commands are applied in the order they are sent, and the sender waits until its command has been
+// applied and the new state published, so a Bank of Actors makes the same decisions as a Bank of cars.
+//
+// An Actor is itself a Member, so a Bank can supervise its cars as Actors; see [WithActors].
+type Actor struct {
+	car      Member
+	commands chan command
+	done     chan struct{}
+
+	sending sync.RWMutex // held by senders, and by Stop to close commands
+	stopped bool
+
+	mu       sync.Mutex // guards what follows
+	state    CarState
+	watchers map[chan CarState]struct{}
+}
+
+// command is a change or question for the car, closing done once the controller has applied it.
+type command struct {
+	apply func(Member)
+	done  chan struct{}
+}
+
+// NewActor starts a controller for the car. Nothing else may touch the car until the Actor is stopped.
+func NewActor(c Member) *Actor {
+	a := Actor{
+		car:      c,
+		commands: make(chan command),
+		done:     make(chan struct{}),
+		state:    snapshot(c),
+		watchers: map[chan CarState]struct{}{},
+	}
+	go a.run()
+	return &a
+}
+
+// run is the controller, applying commands to the car until the Actor is stopped.
+func (a *Actor) run() {
+	defer close(a.done)
+	for cmd := range a.commands {
+		cmd.apply(a.car)
+		a.publish(snapshot(a.car))
+		close(cmd.done)
+	}
+}
+
+// publish makes the state the latest and hands it to every watcher, replacing any state they have yet to receive.
+func (a *Actor) publish(state CarState) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.state = state
+	for ch := range a.watchers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- state
+	}
+}
+
+// send has the controller apply the function to the car and waits until it has.
+// Once the Actor is stopped, the function is applied in the sender's goroutine instead.
+func (a *Actor) send(apply func(Member)) {
+	a.sending.RLock()
+	defer a.sending.RUnlock()
+
+	if a.stopped {
+		apply(a.car)
+		a.publish(snapshot(a.car))
+		return
+	}
+	done := make(chan struct{})
+	a.commands <- command{apply, done}
+	<-done
+}
+
+// Stop stops the controller once it has applied the commands already sent, and closes the watchers' channels.
+// Commands sent afterwards are applied to the car in the sender's goroutine, without a controller to keep
+// them in order, so a stopped Actor should only be used by one goroutine.
+func (a *Actor) Stop() {
+	a.sending.Lock()
+	defer a.sending.Unlock()
+	if a.stopped {
+		return
+	}
+	a.stopped = true
+	close(a.commands)
+	<-a.done
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for ch := range a.watchers {
+		close(ch)
+	}
+	a.watchers = nil
+}
+
+// State returns the state the controller last published.
+func (a *Actor) State() CarState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	state := a.state
+	state.Calls = slices.Clone(state.Calls)
+	return state
+}
+
+// Watch returns a channel receiving the car's state each time the controller publishes it. A watcher who falls
+// behind misses the states in between and receives only the latest. Stop the watch with the returned function.
+func (a *Actor) Watch() (<-chan CarState, func()) {
+	ch := make(chan CarState, 1)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.watchers == nil {
+		// stopped
+		close(ch)
+		return ch, func() {}
+	}
+	a.watchers[ch] = struct{}{}
+
+	return ch, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		delete(a.watchers, ch)
+	}
+}
+
+func (a *Actor) Floor() int               { return a.State().Floor }
+func (a *Actor) Direction() car.Direction { return a.State().Direction }
+func (a *Actor) Status() car.Status       { return a.State().Status }
+func (a *Actor) Door() car.Door           { return a.State().Door }
+func (a *Actor) Mode() car.Mode           { return a.State().Mode }
+func (a *Actor) Fault() car.Fault         { return a.State().Fault }
+func (a *Actor) Load() int                { return a.State().Load }
+func (a *Actor) Capacity() int            { return a.State().Capacity }
+func (a *Actor) Calls() []int             { return a.State().Calls }
+func (a *Actor) Energy() car.Meter        { return a.State().Energy }
+
+func (a *Actor) Score(floor int, direction car.Direction) (eta time.Duration) {
+	a.send(func(c Member) { eta = c.Score(floor, direction) })
+	return eta
+}
+
+func (a *Actor) EstimateEnergy(floor int, direction car.Direction) (wh float64) {
+	a.send(func(c Member) { wh = c.EstimateEnergy(floor, direction) })
+	return wh
+}
+
+func (a *Actor) Reaches(floor int) (reaches bool) {
+	a.send(func(c Member) { reaches = c.Reaches(floor) })
+	return reaches
+}
+
+func (a *Actor) NumFloors() (n int) {
+	a.send(func(c Member) { n = c.NumFloors() })
+	return n
+}
+
+func (a *Actor) Call(floor int) (buttons []bool, err error) {
+	a.send(func(c Member) { buttons, err = c.Call(floor) })
+	return buttons, err
+}
+
+func (a *Actor) Press(floor int) (buttons []bool, err error) {
+	a.send(func(c Member) { buttons, err = c.Press(floor) })
+	return buttons, err
+}
+
+func (a *Actor) Reopen() (reopened bool) {
+	a.send(func(c Member) { reopened = c.Reopen() })
+	return reopened
+}
+
+func (a *Actor) Enter() (entered bool) {
+	a.send(func(c Member) { entered = c.Enter() })
+	return entered
+}
+
+func (a *Actor) CancelCall(floor int)       { a.send(func(c Member) { c.CancelCall(floor) }) }
+func (a *Actor) CancelPress(floor int)      { a.send(func(c Member) { c.CancelPress(floor) }) }
+func (a *Actor) Tick()                      { a.send(Member.Tick) }
+func (a *Actor) SetMode(mode car.Mode)      { a.send(func(c Member) { c.SetMode(mode) }) }
+func (a *Actor) Recall(floor int)           { a.send(func(c Member) { c.Recall(floor) }) }
+func (a *Actor) FireService()               { a.send(Member.FireService) }
+func (a *Actor) Restore()                   { a.send(Member.Restore) }
+func (a *Actor) Fail(fault car.Fault)       { a.send(func(c Member) { c.Fail(fault) }) }
+func (a *Actor) Repair()                    { a.send(Member.Repair) }
+func (a *Actor) Exit()                      { a.send(Member.Exit) }
+func (a *Actor) AddStandby(d time.Duration) { a.send(func(c Member) { c.AddStandby(d) }) }
@@ -2,24 +2,162 @@ package bank
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/dshaneg/elevator/internal/elevator/car"
 )
 
 // Bank represents a collection of elevator cars that are accessed from the same landing.
+//
+// A Bank is safe for concurrent use, so that a real-time driver, an API and a visualization can share it,
+// as long as its cars are too. Each method is applied whole, in some order, while the Bank holds its
+// lock; the lock is never held while waiting on anything but a car.
 type Bank struct {
-	cars []Member
+	mu sync.Mutex
+
+	numFloors int
+	cars      []Member
+	views     []Member          // what dispatch sees of each car: the car itself, or a link to it over the network
+	calls     map[landing]int   // hall calls waiting for a car, keyed to the index of the assigned car
+	dests     map[landing][]int // where passengers waiting at a landing have said they are going; see CallFor
+	mode      Mode
+	recall    int // the designated recall floor for fire service
+	alternate int // the recall floor used when the fire is on the designated floor
+
+	energyWeight float64 // seconds of waiting charged per watt hour a car is expected to use answering a call
+
+	reassignInterval int           // ticks between re-evaluations of waiting hall calls, or 0 for never
+	reassignMargin   time.Duration // how much sooner another car must be to take over a hall call
+	ticks            int
+	logger           *slog.Logger
+	actors           bool     // whether each car runs as an Actor
+	network          *Network // the field bus dispatch goes over, or nil to work with the cars directly
+	rand             *rand.Rand
+}
+
+var (
+	// ErrNoCars is returned by New for a Bank without cars.
+	ErrNoCars = errors.New("elevator: a Bank needs at least one Member to dispatch calls to")
+	// ErrFloorMismatch is returned by New when a car serves a different number of floors than the Bank.
+	ErrFloorMismatch = errors.New("elevator: car and bank floors differ")
+	// ErrFloorOutOfRange is returned for a floor the Bank does not serve.
+	ErrFloorOutOfRange = errors.New("elevator: floor out of range")
+)
+
+// landing identifies a hall call button: a floor and the direction the passenger wants to go.
+type landing struct {
+	floor     int
+	direction car.Direction
 }
 
-// New creates a new Bank with the given number of floors and cars.
-func New(numFloors int, cars []Member) (*Bank, error) {
+// Option is a functional option type that allows us to configure the Bank.
+type Option func(*Bank)
+
+// WithRecallFloors sets the designated and alternate floors cars return to on a fire recall.
+// Both default to floor 0.
+func WithRecallFloors(designated, alternate int) Option {
+	return func(b *Bank) {
+		b.recall = designated
+		b.alternate = alternate
+	}
+}
+
+// WithEnergyWeight makes dispatch energy aware: each car's estimated energy to answer a hall call,
+// in watt hours, is multiplied by the weight and added to its estimated arrival time in seconds.
+// A weight of 0, the default, dispatches on arrival time alone. With regenerative drives the estimate favours cars that would run
+// down full or up empty, since those runs give energy back.
+func WithEnergyWeight(weight float64) Option {
+	return func(b *Bank) {
+		b.energyWeight = weight
+	}
+}
+
+// WithLogger sets the logger that hall call reassignments are reported to. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Bank) {
+		b.logger = logger
+	}
+}
+
+// WithActors runs each car of the Bank as an Actor, its own goroutine receiving the Bank's commands and
+// publishing its state, with the Bank as their supervisor: [Bank.Car] returns the Actors, and [Bank.Close]
+// stops them. The cars must not be touched directly once the Bank has them.
+func WithActors() Option {
+	return func(b *Bank) {
+		b.actors = true
+	}
+}
+
+// New creates a new Bank with the given number of floors and cars, each of which must serve that many floors.
+// It returns an error wrapping ErrFloorOutOfRange if there are no floors or a recall floor is not one of them.
+func New(numFloors int, cars []Member, options ...Option) (*Bank, error) {
 	if len(cars) == 0 {
-		return nil, errors.New("elevator: NewBank requires a non-empty slice of Scorers")
+		return nil, ErrNoCars
+	}
+	if numFloors < 1 {
+		return nil, fmt.Errorf("%w: a Bank must serve at least one floor, not %d", ErrFloorOutOfRange, numFloors)
+	}
+	for i, c := range cars {
+		if n := c.NumFloors(); n != numFloors {
+			return nil, fmt.Errorf("%w: car %d serves %d floors, not %d", ErrFloorMismatch, i, n, numFloors)
+		}
 	}
 	bank := Bank{
-		cars: cars,
+		numFloors:        numFloors,
+		cars:             cars,
+		calls:            map[landing]int{},
+		dests:            map[landing][]int{},
+		reassignInterval: 1,
+		reassignMargin:   DefaultReassignMargin,
+		logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	for _, opt := range options {
+		opt(&bank)
+	}
+	if err := bank.checkFloor(bank.recall); err != nil {
+		return nil, fmt.Errorf("%w (the designated recall floor)", err)
+	}
+	if err := bank.checkFloor(bank.alternate); err != nil {
+		return nil, fmt.Errorf("%w (the alternate recall floor)", err)
+	}
+	if bank.actors || bank.network != nil {
+		for _, c := range cars {
+			if _, ok := c.(Decked); ok {
+				return nil, errors.New("elevator: multi-deck cars cannot run as actors or over a network")
+			}
+		}
+	}
+	if bank.network != nil {
+		if err := bank.network.validate(); err != nil {
+			return nil, err
+		}
+		if bank.rand == nil {
+			bank.rand = rand.New(rand.NewPCG(0, 0))
+		}
+	}
+
+	if bank.actors {
+		bank.cars = make([]Member, len(cars))
+		for i, c := range cars {
+			bank.cars[i] = NewActor(c)
+		}
+	}
+	bank.views = bank.cars
+	if bank.network != nil {
+		bank.views = make([]Member, len(cars))
+		for i, c := range bank.cars {
+			bank.views[i] = newLink(c, numFloors, *bank.network, bank.rand)
+		}
 	}
+
 	return &bank, nil
 }
 
@@ -36,34 +174,275 @@ const (
 	Loading                      // At least one car is loading at the landing.
 )
 
+var landingStatusNames = map[LandingStatus]string{
+	Idle:    "idle",
+	Waiting: "waiting",
+	Loading: "loading",
+}
+
+func (s LandingStatus) String() string {
+	if name, ok := landingStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("LandingStatus(%d)", int(s))
+}
+
+// NoCar is returned by [Bank.Call] when no car can answer the call.
+const NoCar = -1
+
 // Call requests an elevator car to the given floor and in the given direction.
-func (b *Bank) Call(floor int, direction car.Direction) (carIndex int) {
-	lowestScore := math.MaxInt
+// Hall calls are only assigned to working cars whose mode is dispatchable and that can reach the floor,
+// and not at all while the Bank is under fire service. Call returns NoCar when no car can answer, and
+// an error wrapping ErrFloorOutOfRange, with NoCar, for a floor the Bank does not serve.
+func (b *Bank) Call(floor int, direction car.Direction) (carIndex int, err error) {
+	if err := b.checkFloor(floor); err != nil {
+		return NoCar, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.call(landing{floor, direction}), nil
+}
 
-	for i, car := range b.cars {
-		score := car.Score(floor, direction)
-		if score < lowestScore {
-			lowestScore = score
-			carIndex = i
+// CallFor requests a car as Call does, for a passenger who has said where they are going, as at the keypad of
+// a destination dispatch system. The call is only assigned to a car that can reach every destination asked for
+// at the landing, which matters when not every car reaches every floor, as with twin cars sharing a Shaft.
+func (b *Bank) CallFor(floor int, direction car.Direction, destination int) (carIndex int, err error) {
+	if err := b.checkFloor(floor); err != nil {
+		return NoCar, err
+	}
+	if err := b.checkFloor(destination); err != nil {
+		return NoCar, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	l := landing{floor, direction}
+	if !slices.Contains(b.dests[l], destination) {
+		b.dests[l] = append(b.dests[l], destination)
+	}
+
+	carIndex = b.call(l)
+	if carIndex == NoCar {
+		b.forget(l)
+	}
+	return carIndex, nil
+}
+
+// checkFloor returns an error wrapping ErrFloorOutOfRange if the Bank does not serve the floor.
+func (b *Bank) checkFloor(floor int) error {
+	if floor < 0 || floor >= b.numFloors {
+		return fmt.Errorf("%w: %d is not one of the %d floors served", ErrFloorOutOfRange, floor, b.numFloors)
+	}
+	return nil
+}
+
+func (b *Bank) call(l landing) int {
+	if b.mode != Normal {
+		return NoCar
+	}
+
+	carIndex := b.bestCar(l.floor, l.direction, NoCar)
+	if carIndex == NoCar {
+		return NoCar
+	}
+
+	if _, err := b.views[carIndex].Call(l.floor); err != nil {
+		b.logger.Error("hall call not sent", "floor", l.floor, "direction", l.direction, "car", carIndex, "error", err)
+		return NoCar
+	}
+	b.calls[l] = carIndex
+
+	return carIndex
+}
+
+// forget drops the hall call at the landing, along with the destinations asked for there.
+func (b *Bank) forget(l landing) {
+	delete(b.calls, l)
+	delete(b.dests, l)
+}
+
+// reaches reports whether the car can stop at the landing's floor and at every destination asked for there.
+func (b *Bank) reaches(c Member, l landing) bool {
+	if !c.Reaches(l.floor) {
+		return false
+	}
+	for _, floor := range b.dests[l] {
+		if !c.Reaches(floor) {
+			return false
 		}
 	}
+	return true
+}
+
+// dispatchable reports whether the car can be assigned hall calls.
+func dispatchable(c Member) bool {
+	return c.Mode().Dispatchable() && c.Fault() == car.NoFault
+}
+
+// bestCar returns the index of the dispatchable car with the lowest cost for the call,
+// passing over the car at the excluded index, or NoCar if there is none.
+// Cars with room for another passenger are preferred over full ones.
+func (b *Bank) bestCar(floor int, direction car.Direction, excluded int) int {
+	carIndex := NoCar
+	lowestCost := math.Inf(1)
+	full := true
 
-	b.cars[carIndex].Call(floor)
+	for i, c := range b.views {
+		if i == excluded || !dispatchable(c) || !b.reaches(c, landing{floor, direction}) {
+			continue
+		}
+		cost := b.cost(c, floor, direction)
+		if carIndex == NoCar || (full && !isFull(c)) || (full == isFull(c) && cost < lowestCost) {
+			lowestCost = cost
+			carIndex = i
+			full = isFull(c)
+		}
+	}
 
 	return carIndex
 }
 
+// cost weighs how long the car would take to answer the call, in seconds, against the energy it would use.
+func (b *Bank) cost(c Member, floor int, direction car.Direction) float64 {
+	cost := c.Score(floor, direction).Seconds()
+	if b.energyWeight != 0 {
+		cost += b.energyWeight * c.EstimateEnergy(floor, direction)
+	}
+	return cost
+}
+
 // Status returns the status of the landing at the given floor and for the given direction.
+//
+// The car assigned to a hall call opens for that call even if it arrives pointing the other way,
+// such as a car that went up to answer a down call at the top of its run.
 func (b *Bank) Status(floor int, direction car.Direction) (status LandingStatus, c Member) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	l := landing{floor, direction}
+	if i, ok := b.calls[l]; ok && b.isLoadingAt(b.cars[i], floor) {
+		return Loading, at(b.cars[i], floor)
+	}
+
 	for _, c := range b.cars {
-		if c.Floor() == floor && c.Direction() == direction && c.Status() == car.Loading {
-			return Loading, c
+		if b.isLoadingAt(c, floor) && c.Direction() == direction {
+			return Loading, at(c, floor)
+		}
+	}
+
+	if _, ok := b.calls[l]; ok {
+		return Waiting, nil
+	}
+	return Idle, nil
+}
+
+// Assigned returns the index of the car assigned to the hall call at the given floor and for the given direction,
+// or NoCar if there is no such call.
+func (b *Bank) Assigned(floor int, direction car.Direction) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i, ok := b.calls[landing{floor, direction}]; ok {
+		return i
+	}
+	return NoCar
+}
+
+// Tick advances every car in the Bank by one step, first moving any waiting hall calls that
+// another car can now answer better; see [WithReassignment].
+//
+// A hall call is answered once its car has spent a step Loading at the landing and closes its doors.
+func (b *Bank) Tick() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ticks++
+	if b.mode == Normal && b.reassignInterval > 0 && b.ticks%b.reassignInterval == 0 {
+		b.reassess()
+	}
+
+	answered := []landing{}
+	for l, i := range b.calls {
+		if b.isLoadingAt(b.cars[i], l.floor) {
+			answered = append(answered, l)
+		}
+	}
+
+	for _, c := range b.views {
+		c.Tick()
+	}
+	b.handOnRejected()
+
+	for _, l := range answered {
+		if !b.isLoadingAt(b.cars[b.calls[l]], l.floor) {
+			b.forget(l)
+		}
+	}
+}
+
+func (b *Bank) isLoadingAt(c Member, floor int) bool {
+	return at(c, floor) != nil && c.Status() == car.Loading
+}
+
+// at returns the car if it is at the floor, or the deck of a multi-deck car that is, or else nil.
+func at(c Member, floor int) Member {
+	d, ok := c.(Decked)
+	if !ok {
+		if c.Floor() == floor {
+			return c
+		}
+		return nil
+	}
+	for _, deck := range d.Decks() {
+		if deck.Floor() == floor {
+			return deck
+		}
+	}
+	return nil
+}
+
+// NumFloors returns the number of floors the Bank serves.
+func (b *Bank) NumFloors() int {
+	return b.numFloors
+}
+
+// NumCars returns the number of cars in the Bank.
+func (b *Bank) NumCars() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.cars)
+}
+
+// Close stops the Actors of a Bank created WithActors, once they have applied the commands already sent.
+// The Bank must not be used afterwards. Close does nothing to a Bank of plain cars.
+func (b *Bank) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, c := range b.cars {
+		if a, ok := c.(*Actor); ok && b.actors {
+			a.Stop()
+		}
+	}
+}
+
+// Index returns the index of the given car, or of the car the given deck is part of, or NoCar if it is not in the Bank.
+func (b *Bank) Index(c Member) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, member := range b.cars {
+		if member == c {
+			return i
+		}
+		if d, ok := member.(Decked); ok {
+			for _, deck := range d.Decks() {
+				if Member(deck) == c {
+					return i
+				}
+			}
 		}
 	}
-	return Waiting, nil
+	return NoCar
 }
 
 // Car returns the car at the given index. (this feels too low-level)
 func (b *Bank) Car(carIndex int) Member {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.cars[carIndex]
 }
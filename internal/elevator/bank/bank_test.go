@@ -1,9 +1,15 @@
 package bank_test
 
 import (
+	"bytes"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dshaneg/elevator/internal/elevator/bank"
 	"github.com/dshaneg/elevator/internal/elevator/bank/stubs"
@@ -17,34 +23,122 @@ func TestCallError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// newCar creates a Car, failing the test if the options are invalid.
+func newCar(t *testing.T, numFloors int, options ...car.Option) *car.Car {
+	t.Helper()
+	c, err := car.NewCar(numFloors, options...)
+	require.NoError(t, err)
+	return c
+}
+
+func newDoubleDeck(t *testing.T, numFloors, lobby int) *car.DoubleDeck {
+	t.Helper()
+	d, err := car.NewDoubleDeck(numFloors, lobby)
+	require.NoError(t, err)
+	return d
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		numFloors int
+		cars      []bank.Member
+		options   []bank.Option
+		expected  error
+	}{
+		{
+			name:      "No floors",
+			numFloors: 0,
+			cars:      []bank.Member{stubs.NewCar(0)},
+			expected:  bank.ErrFloorOutOfRange,
+		},
+		{
+			name:      "No cars",
+			numFloors: 5,
+			cars:      []bank.Member{},
+			expected:  bank.ErrNoCars,
+		},
+		{
+			name:      "A car serving fewer floors",
+			numFloors: 5,
+			cars:      []bank.Member{newCar(t, 5), newCar(t, 4)},
+			expected:  bank.ErrFloorMismatch,
+		},
+		{
+			name:      "A double-deck car serving more floors",
+			numFloors: 5,
+			cars:      []bank.Member{newDoubleDeck(t, 6, 0)},
+			expected:  bank.ErrFloorMismatch,
+		},
+		{
+			name:      "A designated recall floor out of range",
+			numFloors: 5,
+			cars:      []bank.Member{newCar(t, 5)},
+			options:   []bank.Option{bank.WithRecallFloors(5, 1)},
+			expected:  bank.ErrFloorOutOfRange,
+		},
+		{
+			name:      "An alternate recall floor out of range",
+			numFloors: 5,
+			cars:      []bank.Member{newCar(t, 5)},
+			options:   []bank.Option{bank.WithRecallFloors(0, -1)},
+			expected:  bank.ErrFloorOutOfRange,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := bank.New(tc.numFloors, tc.cars, tc.options...)
+			assert.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestCallOutOfRange(t *testing.T) {
+	c := stubs.NewCar(0)
+	b, err := bank.New(5, []bank.Member{c})
+	require.NoError(t, err)
+
+	for _, floor := range []int{-1, 5} {
+		carIndex, err := b.Call(floor, car.Up)
+		assert.ErrorIs(t, err, bank.ErrFloorOutOfRange)
+		assert.Equal(t, bank.NoCar, carIndex)
+
+		_, err = b.CallFor(2, car.Up, floor)
+		assert.ErrorIs(t, err, bank.ErrFloorOutOfRange)
+	}
+	assert.Equal(t, 0, c.CallCount)
+	assert.Equal(t, bank.NoCar, b.Assigned(2, car.Up), "nothing is left waiting")
+}
+
 var bankCases = []struct {
 	name             string
 	numFloors        int
-	scores           []int
+	scores           []time.Duration
 	expectedCarIndex int
 }{
 	{
 		name:             "Returns 0th index when it has the lowest score",
 		numFloors:        5,
-		scores:           []int{0, 10, 20, 100},
+		scores:           []time.Duration{0, 10, 20, 100},
 		expectedCarIndex: 0,
 	},
 	{
 		name:             "Returns first lowest scored index when not 0th index",
 		numFloors:        5,
-		scores:           []int{100, 80, 10, 90},
+		scores:           []time.Duration{100, 80, 10, 90},
 		expectedCarIndex: 2,
 	},
 	{
 		name:             "Works with negative scores",
 		numFloors:        5,
-		scores:           []int{100, 80, 10, -90},
+		scores:           []time.Duration{100, 80, 10, -90},
 		expectedCarIndex: 3,
 	},
 	{
 		name:             "Returns first lowest scored index when there's a tie",
 		numFloors:        5,
-		scores:           []int{100, 10, 10, 90},
+		scores:           []time.Duration{100, 10, 10, 90},
 		expectedCarIndex: 1,
 	},
 }
@@ -64,12 +158,29 @@ func TestCall(t *testing.T) {
 			assert.NoError(t, err)
 
 			// neither parameter matters since using a stub
-			got := b.Call(0, car.Up)
+			got, err := b.Call(0, car.Up)
+			assert.NoError(t, err)
 			assert.Equal(t, tc.expectedCarIndex, got)
 		})
 	}
 }
 
+// call makes a hall call at a floor the Bank serves, returning the index of the car sent.
+func call(t *testing.T, b *bank.Bank, floor int, direction car.Direction) int {
+	t.Helper()
+	carIndex, err := b.Call(floor, direction)
+	require.NoError(t, err)
+	return carIndex
+}
+
+// callFor makes a hall call for a destination as call does.
+func callFor(t *testing.T, b *bank.Bank, floor int, direction car.Direction, destination int) int {
+	t.Helper()
+	carIndex, err := b.CallFor(floor, direction, destination)
+	require.NoError(t, err)
+	return carIndex
+}
+
 func TestCallCallsCarCall(t *testing.T) {
 	c := stubs.NewCar(0)
 	b, err := bank.New(5, []bank.Member{c})
@@ -78,3 +189,723 @@ func TestCallCallsCarCall(t *testing.T) {
 	b.Call(0, car.Up)
 	assert.Equal(t, 1, c.CallCount)
 }
+
+func TestStatusFollowsHallCall(t *testing.T) {
+	const numFloors = 5
+	c := newCar(t, numFloors)
+	b, err := bank.New(numFloors, []bank.Member{c})
+	assert.NoError(t, err)
+
+	status, _ := b.Status(4, car.Down)
+	assert.Equal(t, bank.Idle, status)
+
+	b.Call(4, car.Down)
+	status, _ = b.Status(4, car.Down)
+	assert.Equal(t, bank.Waiting, status)
+
+	// the car travels up to answer the down call, so it arrives pointing up
+	for range 4 {
+		b.Tick()
+	}
+	status, got := b.Status(4, car.Down)
+	assert.Equal(t, bank.Loading, status)
+	assert.Same(t, c, got)
+
+	// once the doors close the call has been answered
+	b.Tick()
+	status, _ = b.Status(4, car.Down)
+	assert.Equal(t, bank.Idle, status)
+}
+
+func TestTickTicksCars(t *testing.T) {
+	c := stubs.NewCar(0)
+	b, err := bank.New(5, []bank.Member{c})
+	assert.NoError(t, err)
+
+	b.Tick()
+	assert.Equal(t, 1, c.TickCount)
+}
+
+func TestRecall(t *testing.T) {
+	tests := []struct {
+		name      string
+		alternate bool
+		expected  int
+	}{
+		{
+			name:     "Recalls to the designated floor",
+			expected: 1,
+		},
+		{
+			name:      "Recalls to the alternate floor",
+			alternate: true,
+			expected:  3,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cars := []*stubs.Car{stubs.NewCar(0), stubs.NewCar(0)}
+			b, err := bank.New(5, []bank.Member{cars[0], cars[1]}, bank.WithRecallFloors(1, 3))
+			assert.NoError(t, err)
+
+			b.Recall(tc.alternate)
+			assert.Equal(t, bank.FireRecall, b.Mode())
+			for _, c := range cars {
+				assert.Equal(t, car.FireRecall, c.Mode())
+				assert.Equal(t, tc.expected, c.Recalled)
+			}
+		})
+	}
+}
+
+func TestRecallCancelsHallCalls(t *testing.T) {
+	c := stubs.NewCar(0)
+	b, err := bank.New(5, []bank.Member{c})
+	assert.NoError(t, err)
+
+	b.Call(3, car.Down)
+	b.Recall(false)
+
+	status, _ := b.Status(3, car.Down)
+	assert.Equal(t, bank.Idle, status)
+	assert.Equal(t, bank.NoCar, call(t, b, 3, car.Down))
+	assert.Equal(t, 1, c.CallCount)
+}
+
+func TestFireService(t *testing.T) {
+	c := stubs.NewCar(0)
+	b, err := bank.New(5, []bank.Member{c})
+	assert.NoError(t, err)
+
+	assert.Error(t, b.FireService(0), "Phase II requires a recall first")
+
+	b.Recall(false)
+	assert.Error(t, b.FireService(1))
+	assert.NoError(t, b.FireService(0))
+	assert.Equal(t, bank.FireService, b.Mode())
+	assert.Equal(t, car.FireService, c.Mode())
+
+	b.Restore()
+	assert.Equal(t, bank.Normal, b.Mode())
+	assert.Equal(t, car.Normal, c.Mode())
+	assert.Equal(t, 0, call(t, b, 3, car.Down))
+}
+
+func TestCallSkipsCarsThatCannotBeDispatched(t *testing.T) {
+	cars := []*stubs.Car{stubs.NewCar(0), stubs.NewCar(10), stubs.NewCar(20)}
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1], cars[2]})
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.SetMode(0, car.OutOfService))
+	assert.NoError(t, b.SetMode(1, car.Independent))
+	assert.Equal(t, 2, call(t, b, 3, car.Up))
+
+	assert.NoError(t, b.SetMode(2, car.Inspection))
+	assert.Equal(t, bank.NoCar, call(t, b, 3, car.Up))
+}
+
+func TestSetModeReassignsHallCalls(t *testing.T) {
+	cars := []*stubs.Car{stubs.NewCar(0), stubs.NewCar(10)}
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, call(t, b, 3, car.Up))
+	assert.NoError(t, b.SetMode(0, car.OutOfService))
+	assert.Equal(t, 1, cars[1].CallCount, "the call moves to the other car")
+
+	status, _ := b.Status(3, car.Up)
+	assert.Equal(t, bank.Waiting, status)
+}
+
+func TestSetModeErrors(t *testing.T) {
+	b, err := bank.New(5, []bank.Member{stubs.NewCar(0)})
+	assert.NoError(t, err)
+
+	assert.Error(t, b.SetMode(1, car.OutOfService))
+	assert.Error(t, b.SetMode(0, car.FireRecall))
+}
+
+func TestSetModeDuringRecall(t *testing.T) {
+	c := newCar(t, 5, car.WithFloor(2))
+	b, err := bank.New(5, []bank.Member{c})
+	require.NoError(t, err)
+	require.NoError(t, b.SetMode(0, car.OutOfService))
+
+	b.Recall(false)
+	assert.NoError(t, b.SetMode(0, car.Independent))
+	assert.Equal(t, car.FireRecall, c.Mode())
+
+	b.Restore()
+	assert.Equal(t, car.Independent, c.Mode(), "switched once restored")
+}
+
+func TestFailReassignsHallCalls(t *testing.T) {
+	cars := []*stubs.Car{stubs.NewCar(0), stubs.NewCar(10)}
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, call(t, b, 3, car.Up))
+	assert.NoError(t, b.Fail(0, car.Stall))
+	assert.Equal(t, car.Stall, cars[0].Fault())
+	assert.Equal(t, 1, cars[1].CallCount)
+	assert.Equal(t, 1, call(t, b, 2, car.Down), "a broken down car is not dispatched")
+
+	assert.NoError(t, b.Repair(0))
+	assert.Equal(t, car.NoFault, cars[0].Fault())
+	assert.Equal(t, 0, call(t, b, 2, car.Down))
+
+	assert.Error(t, b.Fail(0, car.NoFault))
+	assert.Error(t, b.Fail(2, car.Stall))
+	assert.Error(t, b.Repair(-1))
+}
+
+func TestCallWeighsEnergy(t *testing.T) {
+	tests := []struct {
+		name             string
+		weight           float64
+		expectedCarIndex int
+	}{
+		{
+			name:             "Without a weight the nearest car wins",
+			weight:           0,
+			expectedCarIndex: 0,
+		},
+		{
+			name:             "A light weight is not enough to overcome the wait",
+			weight:           0.1,
+			expectedCarIndex: 0,
+		},
+		{
+			name:             "A heavy weight picks the car that saves energy",
+			weight:           1,
+			expectedCarIndex: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// the second car is further away but would give energy back
+			cars := []bank.Member{stubs.NewCarWithEnergy(2*time.Second, 20), stubs.NewCarWithEnergy(6*time.Second, -5)}
+			b, err := bank.New(5, cars, bank.WithEnergyWeight(tc.weight))
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCarIndex, call(t, b, 3, car.Up))
+		})
+	}
+}
+
+func TestTickReassignsHallCalls(t *testing.T) {
+	tests := []struct {
+		name             string
+		options          []bank.Option
+		change           func(cars []*stubs.Car)
+		expectedCarIndex int
+	}{
+		{
+			name:             "Keeps the call when nothing changes",
+			change:           func(cars []*stubs.Car) {},
+			expectedCarIndex: 0,
+		},
+		{
+			name:             "Moves the call to a car that can get there sooner",
+			change:           func(cars []*stubs.Car) { cars[1].CarScore = 0 },
+			expectedCarIndex: 1,
+		},
+		{
+			name:             "Keeps the call when the other car is not sooner by the margin",
+			change:           func(cars []*stubs.Car) { cars[0].CarScore = 30 * time.Second },
+			expectedCarIndex: 0,
+		},
+		{
+			name:             "Moves the call off a full car",
+			change:           func(cars []*stubs.Car) { cars[0].CarLoad = car.DefaultCapacity },
+			expectedCarIndex: 1,
+		},
+		{
+			name:             "Moves the call off a car that has broken down",
+			change:           func(cars []*stubs.Car) { cars[0].CarFault = car.Stall },
+			expectedCarIndex: 1,
+		},
+		{
+			name:             "Keeps the call when reassignment is off",
+			options:          []bank.Option{bank.WithReassignment(0, 0)},
+			change:           func(cars []*stubs.Car) { cars[1].CarScore = 0 },
+			expectedCarIndex: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cars := []*stubs.Car{stubs.NewCar(20 * time.Second), stubs.NewCar(40 * time.Second)}
+			b, err := bank.New(5, []bank.Member{cars[0], cars[1]}, tc.options...)
+			assert.NoError(t, err)
+
+			assert.Equal(t, 0, call(t, b, 3, car.Up))
+			tc.change(cars)
+			b.Tick()
+
+			expectedCalls := []int{1, 0}
+			if tc.expectedCarIndex == 1 {
+				expectedCalls[1] = 1
+			}
+			assert.Equal(t, expectedCalls, []int{cars[0].CallCount, cars[1].CallCount})
+		})
+	}
+}
+
+func TestCallPrefersCarsWithRoom(t *testing.T) {
+	cars := []*stubs.Car{stubs.NewCar(0), stubs.NewCar(time.Minute)}
+	cars[0].CarLoad = car.DefaultCapacity
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, call(t, b, 3, car.Up))
+
+	cars[1].CarLoad = car.DefaultCapacity
+	assert.Equal(t, 0, call(t, b, 2, car.Up), "when every car is full the nearest is sent")
+}
+
+func TestReassignmentIsLogged(t *testing.T) {
+	var log bytes.Buffer
+	cars := []*stubs.Car{stubs.NewCar(20 * time.Second), stubs.NewCar(40 * time.Second)}
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]}, bank.WithLogger(slog.New(slog.NewTextHandler(&log, nil))))
+	assert.NoError(t, err)
+
+	b.Call(3, car.Down)
+	cars[0].CarLoad = car.DefaultCapacity
+	b.Tick()
+
+	assert.Contains(t, log.String(), `msg="hall call reassigned" floor=3 direction=down from=0 to=1 reason="car full"`)
+}
+
+func TestCancel(t *testing.T) {
+	cars := []*stubs.Car{stubs.NewCar(0), stubs.NewCar(time.Minute)}
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]})
+	assert.NoError(t, err)
+
+	assert.False(t, b.Cancel(3, car.Up), "nothing to cancel")
+
+	b.Call(3, car.Up)
+	b.Call(3, car.Down)
+	assert.True(t, b.Cancel(3, car.Up))
+	assert.Empty(t, cars[0].Cancelled, "the car is still answering the down call")
+
+	status, _ := b.Status(3, car.Up)
+	assert.Equal(t, bank.Idle, status)
+
+	assert.True(t, b.Cancel(3, car.Down))
+	assert.Equal(t, []int{3}, cars[0].Cancelled)
+}
+
+func TestReassignmentCancelsTheStop(t *testing.T) {
+	cars := []*stubs.Car{stubs.NewCar(time.Minute), stubs.NewCar(2 * time.Minute)}
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]})
+	assert.NoError(t, err)
+
+	b.Call(3, car.Up)
+	cars[1].CarScore = 0
+	b.Tick()
+
+	assert.Equal(t, []int{3}, cars[0].Cancelled)
+	assert.Empty(t, cars[1].Cancelled)
+}
+
+func TestReopen(t *testing.T) {
+	cars := []*stubs.Car{stubs.NewCar(0), stubs.NewCar(0)}
+	cars[1].CarFloor = 2
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]})
+	assert.NoError(t, err)
+
+	assert.False(t, b.Reopen(2, car.Up), "the car at the landing has already left")
+	assert.False(t, cars[0].Reopened, "the car on another floor is left alone")
+
+	cars[1].CarStatus = car.Loading
+	assert.True(t, b.Reopen(2, car.Up))
+
+	status, c := b.Status(2, car.Up)
+	assert.Equal(t, bank.Loading, status)
+	assert.Same(t, cars[1], c)
+
+	b.Recall(false)
+	assert.False(t, b.Reopen(2, car.Up))
+}
+
+func TestReopenTakesTheCallFromAnotherCar(t *testing.T) {
+	var log bytes.Buffer
+	cars := []*stubs.Car{stubs.NewCar(20 * time.Second), stubs.NewCar(40 * time.Second)}
+	cars[1].CarFloor = 2
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]}, bank.WithLogger(slog.New(slog.NewTextHandler(&log, nil))))
+	require.NoError(t, err)
+
+	b.Call(2, car.Up)
+	require.Equal(t, 0, b.Assigned(2, car.Up))
+
+	cars[1].CarStatus = car.Loading
+	assert.True(t, b.Reopen(2, car.Up))
+
+	assert.Equal(t, 1, b.Assigned(2, car.Up))
+	assert.Equal(t, []int{2}, cars[0].Cancelled, "the car on its way is released from the stop")
+	assert.Empty(t, cars[1].Cancelled)
+	assert.Contains(t, log.String(), `msg="hall call reassigned" floor=2 direction=up from=0 to=1 reason="doors reopened"`)
+}
+
+func TestAssigned(t *testing.T) {
+	b, err := bank.New(5, []bank.Member{stubs.NewCar(20 * time.Second), stubs.NewCar(10 * time.Second)})
+	assert.NoError(t, err)
+
+	assert.Equal(t, bank.NoCar, b.Assigned(3, car.Up))
+
+	b.Call(3, car.Up)
+
+	assert.Equal(t, 1, b.Assigned(3, car.Up))
+	assert.Equal(t, bank.NoCar, b.Assigned(3, car.Down))
+}
+
+// TestConcurrentUse drives a Bank from several goroutines at once, as a real-time loop, an API and a
+// visualization would. It is meant to be run with the race detector.
+func TestConcurrentUse(t *testing.T) {
+	const floors, steps = 10, 200
+	cars := []bank.Member{newCar(t, floors), newCar(t, floors), newCar(t, floors)}
+	b, err := bank.New(floors, cars)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	run := func(f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range steps {
+				f(i)
+			}
+		}()
+	}
+
+	// the simulation loop
+	run(func(int) { b.Tick() })
+	// hall calls coming in from an API
+	run(func(i int) {
+		if i%2 == 0 {
+			b.Call(i%floors, car.Down)
+		} else {
+			b.Call(i%floors, car.Up)
+		}
+	})
+	// passengers pressing buttons and boarding
+	run(func(i int) {
+		c := b.Car(i % b.NumCars())
+		c.Press((i * 7) % floors)
+		if c.Enter() {
+			c.Exit()
+		}
+	})
+	// a visualization polling the landings and cars
+	run(func(i int) {
+		status, c := b.Status(i%floors, car.Up)
+		if status == bank.Loading {
+			assert.NotNil(t, c)
+		}
+		b.Assigned(i%floors, car.Down)
+		for j := range b.NumCars() {
+			c := b.Car(j)
+			c.Floor()
+			c.Calls()
+			c.Score(i%floors, car.Up)
+		}
+		b.Mode()
+	})
+
+	wg.Wait()
+
+	// once everyone has finished, the cars answer every call left
+	for range 4 * floors {
+		b.Tick()
+	}
+	for floor := range floors {
+		for _, direction := range []car.Direction{car.Up, car.Down} {
+			status, _ := b.Status(floor, direction)
+			assert.NotEqual(t, bank.Waiting, status, "floor %d %v", floor, direction)
+		}
+	}
+}
+
+func TestActorsDispatchAsCarsDo(t *testing.T) {
+	const floors = 10
+	newCars := func() []bank.Member {
+		return []bank.Member{newCar(t, floors), newCar(t, floors, car.WithFloor(9)), newCar(t, floors, car.WithFloor(4))}
+	}
+	plain, err := bank.New(floors, newCars())
+	require.NoError(t, err)
+	actors, err := bank.New(floors, newCars(), bank.WithActors())
+	require.NoError(t, err)
+	defer actors.Close()
+
+	_, isActor := actors.Car(0).(*bank.Actor)
+	assert.True(t, isActor)
+
+	for step := range 30 {
+		for _, b := range []*bank.Bank{plain, actors} {
+			if step%3 == 0 {
+				b.Call((step*7)%floors, car.Down)
+				b.Car(step % 3).Press((step * 3) % floors)
+			}
+			b.Tick()
+		}
+		for i := range plain.NumCars() {
+			expected, actual := plain.Car(i), actors.Car(i)
+			assert.Equal(t, expected.Floor(), actual.Floor(), "step %d car %d", step, i)
+			assert.Equal(t, expected.Status(), actual.Status(), "step %d car %d", step, i)
+			assert.Equal(t, expected.Calls(), actual.Calls(), "step %d car %d", step, i)
+		}
+	}
+}
+
+func TestActorPublishesState(t *testing.T) {
+	a := bank.NewActor(newCar(t, 10))
+	states, stop := a.Watch()
+
+	a.Press(3)
+	a.Tick()
+	a.Tick()
+
+	// the watcher was busy, so it gets only the latest state
+	state := <-states
+	assert.Equal(t, 2, state.Floor)
+	assert.Equal(t, car.Traveling, state.Status)
+	assert.Equal(t, []int{3}, state.Calls)
+	select {
+	case <-states:
+		t.Error("states published while the watcher was busy should be merged")
+	default:
+	}
+
+	stop()
+	a.Tick()
+	select {
+	case <-states:
+		t.Error("a watcher who stopped should not be sent states")
+	default:
+	}
+
+	others, _ := a.Watch()
+	a.Stop()
+	a.Stop()
+	_, open := <-others
+	assert.False(t, open, "stopping the Actor closes its watchers")
+
+	// a stopped Actor carries on in the caller's goroutine
+	assert.Equal(t, car.Loading, a.Status())
+	a.Tick()
+	assert.Equal(t, car.Parked, a.Status())
+	assert.Equal(t, 3, a.Floor())
+}
+
+func TestNetworkDispatchesOnStaleStatus(t *testing.T) {
+	const floors = 10
+	newBank := func(options ...bank.Option) *bank.Bank {
+		cars := []bank.Member{newCar(t, floors), newCar(t, floors, car.WithFloor(9))}
+		b, err := bank.New(floors, cars, options...)
+		require.NoError(t, err)
+
+		// the second car comes down to the first floor, which a slow bus has yet to tell its bank
+		b.Car(1).Press(1)
+		for range 8 {
+			b.Tick()
+		}
+		require.Equal(t, 1, b.Car(1).Floor())
+		return b
+	}
+
+	assert.Equal(t, 0, call(t, newBank(), 9, car.Down), "the first car is nearer, having been there all along")
+	assert.Equal(t, 1, call(t, newBank(bank.WithNetwork(bank.Network{Latency: 10}, nil)), 9, car.Down),
+		"the bank still thinks the second car is waiting at the top")
+}
+
+func TestNetworkDelaysCalls(t *testing.T) {
+	b, err := bank.New(10, []bank.Member{newCar(t, 10)}, bank.WithNetwork(bank.Network{Latency: 2}, nil))
+	require.NoError(t, err)
+
+	b.Call(5, car.Up)
+	assert.Empty(t, b.Car(0).Calls())
+	b.Tick()
+	assert.Empty(t, b.Car(0).Calls())
+	b.Tick()
+	assert.Equal(t, []int{5}, b.Car(0).Calls())
+	assert.Equal(t, 1, b.Car(0).Floor(), "the call arrived before the car ticked")
+
+	status, _ := b.Status(5, car.Up)
+	assert.Equal(t, bank.Waiting, status)
+}
+
+func TestNetworkRetriesLostCalls(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	b, err := bank.New(10, []bank.Member{newCar(t, 10)}, bank.WithNetwork(bank.Network{Loss: 0.9}, r))
+	require.NoError(t, err)
+
+	b.Call(3, car.Up)
+	for range 100 {
+		b.Tick()
+		if status, _ := b.Status(3, car.Up); status == bank.Loading {
+			return
+		}
+	}
+	t.Error("the call should get through in the end")
+}
+
+func TestNetworkHandsOnRejectedCalls(t *testing.T) {
+	cars := []*stubs.Car{stubs.NewCar(time.Second), stubs.NewCar(time.Minute)}
+	b, err := bank.New(5, []bank.Member{cars[0], cars[1]}, bank.WithNetwork(bank.Network{Latency: 1}, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, call(t, b, 3, car.Up))
+	// the car can no longer stop at 3 by the time the call reaches it
+	cars[0].Unreachable = []int{3}
+	b.Tick()
+	assert.Equal(t, 0, cars[0].CallCount)
+	assert.Equal(t, 1, b.Assigned(3, car.Up), "the call went to the other car")
+
+	cars[1].Unreachable = []int{3}
+	b.Tick()
+	status, _ := b.Status(3, car.Up)
+	assert.Equal(t, bank.Idle, status, "no car could take the call")
+}
+
+func TestNetworkErrors(t *testing.T) {
+	for _, n := range []bank.Network{{Latency: -1}, {Jitter: -1}, {Loss: -0.1}, {Loss: 1}} {
+		_, err := bank.New(10, []bank.Member{newCar(t, 10)}, bank.WithNetwork(n, nil))
+		assert.Error(t, err, "%+v", n)
+	}
+}
+
+func TestDoubleDeckLoadsAtEitherDeck(t *testing.T) {
+	d := newDoubleDeck(t, 12, 0)
+	b, err := bank.New(12, []bank.Member{newCar(t, 12, car.WithFloor(11)), d})
+	require.NoError(t, err)
+
+	b.Call(4, car.Up)
+	b.Call(5, car.Up)
+	assert.Equal(t, 1, b.Assigned(4, car.Up))
+	assert.Equal(t, 1, b.Assigned(5, car.Up), "the deck above is stopping anyway")
+
+	var status bank.LandingStatus
+	for range 20 {
+		if status, _ = b.Status(4, car.Up); status == bank.Loading {
+			break
+		}
+		b.Tick()
+	}
+	status, c := b.Status(4, car.Up)
+	assert.Equal(t, bank.Loading, status)
+	assert.Same(t, d.Decks()[0], c)
+	status, c = b.Status(5, car.Up)
+	assert.Equal(t, bank.Loading, status)
+	assert.Same(t, d.Decks()[1], c)
+
+	assert.Equal(t, 1, b.Index(c))
+	assert.Equal(t, 1, b.Index(d))
+	assert.Equal(t, bank.NoCar, b.Index(newCar(t, 12)))
+}
+
+func TestDoubleDeckErrors(t *testing.T) {
+	for _, option := range []bank.Option{bank.WithActors(), bank.WithNetwork(bank.Network{}, nil)} {
+		_, err := bank.New(10, []bank.Member{newDoubleDeck(t, 10, 0)}, option)
+		assert.Error(t, err)
+	}
+}
+
+func TestCallOnlySendsCarsThatReach(t *testing.T) {
+	near, far := stubs.NewCar(10*time.Second), stubs.NewCar(20*time.Second)
+	near.Unreachable = []int{0, 9}
+	near.CarFloors, far.CarFloors = 10, 10
+	b, err := bank.New(10, []bank.Member{near, far})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, call(t, b, 4, car.Up))
+	assert.Equal(t, 1, call(t, b, 0, car.Up), "the nearer car cannot reach the floor")
+
+	assert.Equal(t, 0, callFor(t, b, 5, car.Up, 8))
+	assert.Equal(t, 1, callFor(t, b, 5, car.Up, 9), "the nearer car cannot take everyone waiting")
+	assert.Equal(t, 1, call(t, b, 5, car.Up), "the landing still has a passenger for 9")
+
+	b.Cancel(5, car.Up)
+	assert.Equal(t, 0, call(t, b, 5, car.Up), "the passenger for 9 has gone")
+
+	near.Unreachable = []int{0, 9, 6}
+	far.Unreachable = []int{6}
+	assert.Equal(t, bank.NoCar, callFor(t, b, 3, car.Up, 6))
+	assert.Equal(t, 0, call(t, b, 3, car.Up))
+}
+
+func FuzzNew(f *testing.F) {
+	for _, numFloors := range []int{-1, 0, 1, 10} {
+		f.Add(numFloors)
+	}
+
+	f.Fuzz(func(t *testing.T, numFloors int) {
+		c := stubs.NewCar(0)
+		c.CarFloors = numFloors
+		_, err := bank.New(numFloors, []bank.Member{c})
+		if numFloors < 1 {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	})
+}
+
+// dispatch sends a Bank of three cars serving numFloors a sequence of hall calls and steps, one per byte of ops,
+// then lets it run until every call is answered. Each call must go to a car that will stop at the floor.
+func dispatch(t *testing.T, numFloors int, ops []byte) {
+	t.Helper()
+	cars := []bank.Member{newCar(t, numFloors), newCar(t, numFloors), newCar(t, numFloors)}
+	b, err := bank.New(numFloors, cars)
+	require.NoError(t, err)
+
+	for _, op := range ops {
+		floor := int(op>>2) % numFloors
+		direction := car.Up
+		switch op & 3 {
+		case 1:
+			direction = car.Down
+			fallthrough
+		case 0:
+			i, err := b.Call(floor, direction)
+			require.NoError(t, err)
+			require.NotEqual(t, bank.NoCar, i)
+			if status, _ := b.Status(floor, direction); status != bank.Loading {
+				require.Contains(t, b.Car(i).Calls(), floor)
+			}
+		default:
+			b.Tick()
+		}
+	}
+
+	for range 4 * numFloors {
+		b.Tick()
+	}
+	for floor := range numFloors {
+		for _, direction := range []car.Direction{car.Up, car.Down} {
+			assert.Equal(t, bank.NoCar, b.Assigned(floor, direction), "%v call at floor %d", direction, floor)
+		}
+	}
+}
+
+func FuzzBankCall(f *testing.F) {
+	f.Add(uint8(5), []byte{0x0c, 0x02, 0x11, 0x02, 0x02, 0x00})
+	f.Add(uint8(10), []byte{0x24, 0x25, 0x02, 0x08, 0x1d, 0x02, 0x00, 0x01})
+
+	f.Fuzz(func(t *testing.T, numFloors uint8, ops []byte) {
+		dispatch(t, 2+int(numFloors)%31, ops)
+	})
+}
+
+func TestDispatchOverRandomCalls(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for range 200 {
+		ops := make([]byte, r.IntN(64))
+		for i := range ops {
+			ops[i] = byte(r.IntN(256))
+		}
+		dispatch(t, 2+r.IntN(20), ops)
+	}
+}
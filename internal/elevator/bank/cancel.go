@@ -0,0 +1,64 @@
+package bank
+
+import "github.com/dshaneg/elevator/internal/elevator/car"
+
+// Cancel takes back the hall call at the given floor and direction, as when every passenger waiting
+// there has given up. It returns false if there was no call waiting. The car that was sent keeps
+// the stop only if it has other business there.
+func (b *Bank) Cancel(floor int, direction car.Direction) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	l := landing{floor, direction}
+	i, ok := b.calls[l]
+	if !ok {
+		return false
+	}
+
+	b.forget(l)
+	b.release(floor, i)
+	return true
+}
+
+// Reopen opens the doors of a car that is closing them at the given floor, for a passenger who has just
+// reached the landing wanting to go in the given direction. The car must be headed that way, or be
+// free to go either way, and have room, and it is assigned the hall call so that the passenger can board.
+// A different car that was on its way to answer the call is released from the stop. Reopen returns false
+// if there is no such car.
+func (b *Bank) Reopen(floor int, direction car.Direction) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.mode != Normal {
+		return false
+	}
+
+	for i, c := range b.cars {
+		if at(c, floor) == nil || !dispatchable(c) || isFull(c) {
+			continue
+		}
+		if c.Direction() != direction && c.Status() != car.Parked {
+			continue
+		}
+		if c.Reopen() {
+			l := landing{floor, direction}
+			prev, ok := b.calls[l]
+			b.calls[l] = i
+			if ok && prev != i {
+				b.release(floor, prev)
+				b.logger.Info("hall call reassigned", "floor", floor, "direction", direction, "from", prev, "to", i, "reason", "doors reopened")
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// release cancels the stop the car at the given index was making at the floor for hall calls,
+// unless it is still answering one there.
+func (b *Bank) release(floor, carIndex int) {
+	for l, i := range b.calls {
+		if i == carIndex && l.floor == floor {
+			return
+		}
+	}
+	b.views[carIndex].CancelCall(floor)
+}
@@ -0,0 +1,78 @@
+package bank
+
+import "fmt"
+
+// Mode is an enum type that represents the operating mode of the Bank.
+type Mode int
+
+const (
+	Normal      Mode = iota // hall calls are dispatched to the cars as usual.
+	FireRecall              // Phase I: every car is recalled and hall calls are ignored.
+	FireService             // Phase II: a firefighter is operating at least one recalled car.
+)
+
+var modeNames = map[Mode]string{
+	Normal:      "normal",
+	FireRecall:  "fire recall",
+	FireService: "fire service",
+}
+
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Mode returns the operating mode of the Bank.
+func (b *Bank) Mode() Mode {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.mode
+}
+
+// Recall starts Phase I emergency recall. Every hall call is cancelled and every car returns non-stop
+// to the designated recall floor, or the alternate floor when the fire is on the designated floor.
+func (b *Bank) Recall(alternate bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	floor := b.recall
+	if alternate {
+		floor = b.alternate
+	}
+
+	b.mode = FireRecall
+	clear(b.calls)
+	clear(b.dests)
+	for _, c := range b.cars {
+		c.Recall(floor)
+	}
+}
+
+// FireService starts Phase II operation of the car at the given index, handing it over to a firefighter.
+// The Bank must already be in recall.
+func (b *Bank) FireService(carIndex int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.mode == Normal {
+		return fmt.Errorf("elevator: car %d cannot enter fire service before a recall", carIndex)
+	}
+	if carIndex < 0 || carIndex >= len(b.cars) {
+		return fmt.Errorf("elevator: no car at index %d", carIndex)
+	}
+
+	b.mode = FireService
+	b.cars[carIndex].FireService()
+	return nil
+}
+
+// Restore returns the Bank to Normal mode, and each of its cars to the mode it was in before the recall,
+// so that a car down for maintenance stays down.
+func (b *Bank) Restore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mode = Normal
+	for _, c := range b.cars {
+		c.Restore()
+	}
+}
@@ -1,6 +1,10 @@
 package bank
 
-import "github.com/dshaneg/elevator/internal/elevator/car"
+import (
+	"time"
+
+	"github.com/dshaneg/elevator/internal/elevator/car"
+)
 
 // type Scorer interface {
 // 	Score(floor int, direction Direction) int
@@ -8,9 +12,41 @@ import "github.com/dshaneg/elevator/internal/elevator/car"
 
 // ElevatorController is an interface with two methods: Call and Move
 type Member interface {
-	Score(floor int, direction car.Direction) int
-	Call(floor int) []bool
+	Score(floor int, direction car.Direction) time.Duration
+	EstimateEnergy(floor int, direction car.Direction) float64
+	Call(floor int) ([]bool, error)
+	CancelCall(floor int)
+	Press(floor int) ([]bool, error)
+	CancelPress(floor int)
+	Reopen() bool
+	Calls() []int
 	Floor() int
 	Direction() car.Direction
 	Status() car.Status
+	Door() car.Door
+	Mode() car.Mode
+	Fault() car.Fault
+	Load() int
+	Capacity() int
+	Reaches(floor int) bool
+	NumFloors() int
+	Energy() car.Meter
+	Tick()
+	SetMode(mode car.Mode)
+	Recall(floor int)
+	FireService()
+	Restore()
+	Fail(fault car.Fault)
+	Repair()
+	Enter() bool
+	Exit()
+	AddStandby(d time.Duration)
+}
+
+// Decked is a Member with more than one deck, such as car.DoubleDeck, whose decks stop together, each at its own floor.
+// Passengers board and ride the decks, so the Bank reports the deck at a landing rather than the car.
+type Decked interface {
+	Member
+	Decks() []*car.Deck
+	DeckFor(floor int) *car.Deck // the deck that serves the floor
 }
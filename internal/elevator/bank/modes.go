@@ -0,0 +1,65 @@
+package bank
+
+import (
+	"fmt"
+
+	"github.com/dshaneg/elevator/internal/elevator/car"
+)
+
+// SetMode switches the car at the given index to one of the operating modes, such as taking it
+// out of service for maintenance. Hall calls waiting on a car that can no longer be dispatched
+// are handed to the next best car. During a fire recall, the car switches once the Bank is restored.
+func (b *Bank) SetMode(carIndex int, mode car.Mode) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if carIndex < 0 || carIndex >= len(b.cars) {
+		return fmt.Errorf("elevator: no car at index %d", carIndex)
+	}
+	if mode == car.FireRecall || mode == car.FireService {
+		return fmt.Errorf("elevator: fire modes are entered with Recall and FireService, not SetMode")
+	}
+
+	b.cars[carIndex].SetMode(mode)
+	if b.mode == Normal && !mode.Dispatchable() {
+		b.reassign(carIndex, mode.String())
+	}
+	return nil
+}
+
+// reassign hands the hall calls waiting on the car at the given index to other cars.
+// Calls that no other car can answer are dropped, and passengers will have to call again.
+func (b *Bank) reassign(carIndex int, reason string) {
+	for _, l := range b.landings() {
+		if b.calls[l] == carIndex {
+			b.move(l, carIndex, reason)
+		}
+	}
+}
+
+// Fail breaks down the car at the given index, handing its hall calls to the next best car.
+func (b *Bank) Fail(carIndex int, fault car.Fault) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if carIndex < 0 || carIndex >= len(b.cars) {
+		return fmt.Errorf("elevator: no car at index %d", carIndex)
+	}
+	if fault == car.NoFault {
+		return fmt.Errorf("elevator: car %d cannot fail without a fault", carIndex)
+	}
+
+	b.cars[carIndex].Fail(fault)
+	b.reassign(carIndex, fault.String())
+	return nil
+}
+
+// Repair fixes the car at the given index so it can be dispatched again.
+func (b *Bank) Repair(carIndex int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if carIndex < 0 || carIndex >= len(b.cars) {
+		return fmt.Errorf("elevator: no car at index %d", carIndex)
+	}
+
+	b.cars[carIndex].Repair()
+	return nil
+}
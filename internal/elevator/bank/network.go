@@ -0,0 +1,231 @@
+package bank
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/elevator/car"
+)
+
+// Network describes the field bus between a Bank and its car controllers. Latency and Jitter are counted in
+// ticks of the Bank, the only clock it has.
+type Network struct {
+	Latency int     // ticks every message takes to arrive
+	Jitter  int     // up to this many more ticks a message may take, at random
+	Loss    float64 // the chance each message is lost, from 0 to 1
+}
+
+// WithNetwork has the Bank dispatch over a simulated field bus: it decides which car answers a hall call from
+// the status each car last reported over the Network, and its calls reach the cars after a delay. Each car reports
+// its status once a tick. A lost report leaves the Bank with the older one, while a lost command is sent again the
+// next tick, as a bus retries until it is acknowledged, and commands arrive in the order they were sent. A car that
+// turns down a hall call says so in its acknowledgement, and the Bank hands the call to another car.
+//
+// Only dispatch goes over the Network. Passengers see the cars themselves, as do [Bank.Car] and [Bank.Status];
+// mode changes and fire service are hard-wired, and a hall call is answered when its car actually loads at the landing.
+//
+// The random numbers for jitter and loss are drawn from r, which may be nil for a Network with neither.
+func WithNetwork(n Network, r *rand.Rand) Option {
+	return func(b *Bank) {
+		b.network = &n
+		b.rand = r
+	}
+}
+
+// validate checks that the Network's messages can get through.
+func (n Network) validate() error {
+	if n.Latency < 0 || n.Jitter < 0 {
+		return fmt.Errorf("elevator: network latency %d and jitter %d cannot be negative", n.Latency, n.Jitter)
+	}
+	if n.Loss < 0 || n.Loss >= 1 {
+		return fmt.Errorf("elevator: network loss %v must be at least 0 and below 1", n.Loss)
+	}
+	return nil
+}
+
+// link is a car as the Bank sees it over a Network: its status as of the last report to arrive, and its
+// calls once they have reached it. Everything else goes straight to the car.
+type link struct {
+	Member
+
+	network  Network
+	rand     *rand.Rand
+	floors   int
+	ticks    int
+	commands []message // in flight to the car, in order of arrival
+	reports  []report  // in flight to the Bank
+	view     report    // the latest report to arrive
+	rejected []int     // floors of the hall calls the car has turned down, for the Bank to hand on
+}
+
+// message is a command on its way to the car.
+type message struct {
+	floor   int // the floor the command is for
+	apply   func(Member) error
+	arrives int
+}
+
+// report is a car's status as sent to the Bank, including its estimates for answering a call at every landing.
+type report struct {
+	sent, arrives int
+	state         CarState
+	scores        []time.Duration // by landingIndex
+	energy        []float64       // by landingIndex
+}
+
+func newLink(c Member, floors int, n Network, r *rand.Rand) *link {
+	l := link{Member: c, network: n, rand: r, floors: floors}
+	l.view = l.report()
+	return &l
+}
+
+// landingIndex indexes the estimates of a report by floor and direction.
+func landingIndex(floor int, direction car.Direction) int {
+	if direction == car.Up {
+		return 2*floor + 1
+	}
+	return 2 * floor
+}
+
+// report takes the car's status now.
+func (l *link) report() report {
+	r := report{
+		sent:   l.ticks,
+		state:  snapshot(l.Member),
+		scores: make([]time.Duration, 2*l.floors),
+		energy: make([]float64, 2*l.floors),
+	}
+	for floor := range l.floors {
+		for _, direction := range []car.Direction{car.Down, car.Up} {
+			i := landingIndex(floor, direction)
+			r.scores[i] = l.Member.Score(floor, direction)
+			r.energy[i] = l.Member.EstimateEnergy(floor, direction)
+		}
+	}
+	return r
+}
+
+// delay draws how many ticks a message takes to arrive. A lossy message may be lost, while any other is sent
+// again the next tick each time it is lost.
+func (l *link) delay(lossy bool) (ticks int, lost bool) {
+	for l.network.Loss > 0 && l.rand.Float64() < l.network.Loss {
+		if lossy {
+			return 0, true
+		}
+		ticks++
+	}
+	ticks += l.network.Latency
+	if l.network.Jitter > 0 {
+		ticks += l.rand.IntN(l.network.Jitter + 1)
+	}
+	return ticks, false
+}
+
+// send sends a command for the floor to the car, applying it straight away if it takes no time to arrive,
+// in which case it returns the car's error.
+func (l *link) send(floor int, apply func(Member) error) error {
+	ticks, _ := l.delay(false)
+	arrives := l.ticks + ticks
+	if len(l.commands) > 0 {
+		// keep the commands in order
+		arrives = max(arrives, l.commands[len(l.commands)-1].arrives)
+	}
+	if arrives == l.ticks && len(l.commands) == 0 {
+		return apply(l.Member)
+	}
+	l.commands = append(l.commands, message{floor, apply, arrives})
+	return nil
+}
+
+// Tick delivers the commands that have arrived, ticks the car, and sends its report.
+func (l *link) Tick() {
+	l.ticks++
+	for len(l.commands) > 0 && l.commands[0].arrives <= l.ticks {
+		if err := l.commands[0].apply(l.Member); err != nil {
+			l.rejected = append(l.rejected, l.commands[0].floor)
+		}
+		l.commands = l.commands[1:]
+	}
+
+	l.Member.Tick()
+
+	if ticks, lost := l.delay(true); !lost {
+		r := l.report()
+		r.arrives = l.ticks + ticks
+		l.reports = append(l.reports, r)
+	}
+	pending := l.reports[:0]
+	for _, r := range l.reports {
+		switch {
+		case r.arrives > l.ticks:
+			pending = append(pending, r)
+		case r.sent > l.view.sent:
+			l.view = r
+		}
+	}
+	l.reports = pending
+}
+
+// Call sends the car to the floor for a hall call. It returns no buttons, as they are not known until the
+// car reports them. It returns an error for a floor outside the bank, or the car's own error for a call that
+// arrives straight away; a call the car turns down later is left for the Bank to collect with takeRejected.
+func (l *link) Call(floor int) ([]bool, error) {
+	if floor < 0 || floor >= l.floors {
+		return nil, fmt.Errorf("%w: %d is not one of the %d floors served", car.ErrFloorOutOfRange, floor, l.floors)
+	}
+	return nil, l.send(floor, func(c Member) error {
+		_, err := c.Call(floor)
+		return err
+	})
+}
+
+func (l *link) CancelCall(floor int) {
+	l.send(floor, func(c Member) error {
+		c.CancelCall(floor)
+		return nil
+	})
+}
+
+// takeRejected returns the floors of the hall calls the car has turned down since it was last asked.
+func (l *link) takeRejected() []int {
+	rejected := l.rejected
+	l.rejected = nil
+	return rejected
+}
+
+// handOnRejected hands each hall call a car has turned down over the Network to the next best car.
+func (b *Bank) handOnRejected() {
+	for i, v := range b.views {
+		lk, ok := v.(*link)
+		if !ok {
+			continue
+		}
+		for _, floor := range lk.takeRejected() {
+			for _, l := range b.landings() {
+				if l.floor == floor && b.calls[l] == i {
+					b.move(l, i, "call rejected")
+				}
+			}
+		}
+	}
+}
+
+func (l *link) Score(floor int, direction car.Direction) time.Duration {
+	return l.view.scores[landingIndex(floor, direction)]
+}
+
+func (l *link) EstimateEnergy(floor int, direction car.Direction) float64 {
+	return l.view.energy[landingIndex(floor, direction)]
+}
+
+func (l *link) Floor() int               { return l.view.state.Floor }
+func (l *link) Direction() car.Direction { return l.view.state.Direction }
+func (l *link) Status() car.Status       { return l.view.state.Status }
+func (l *link) Door() car.Door           { return l.view.state.Door }
+func (l *link) Mode() car.Mode           { return l.view.state.Mode }
+func (l *link) Fault() car.Fault         { return l.view.state.Fault }
+func (l *link) Load() int                { return l.view.state.Load }
+func (l *link) Capacity() int            { return l.view.state.Capacity }
+func (l *link) Calls() []int             { return l.view.state.Calls }
+func (l *link) Energy() car.Meter        { return l.view.state.Energy }
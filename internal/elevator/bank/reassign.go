@@ -0,0 +1,96 @@
+package bank
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// DefaultReassignMargin is how much sooner another car must be able to answer a hall call
+// before the call is moved to it. The margin keeps calls from bouncing between cars whose
+// estimates are close.
+const DefaultReassignMargin = 15 * time.Second
+
+// WithReassignment sets how often, in ticks, the Bank re-evaluates the hall calls still waiting for a car,
+// and how much sooner another car must be able to answer one before the call is moved to it.
+// By default calls are re-evaluated every tick with the DefaultReassignMargin. An interval of 0
+// makes every assignment permanent, unless the car is taken out of service or breaks down.
+func WithReassignment(interval int, margin time.Duration) Option {
+	return func(b *Bank) {
+		b.reassignInterval = interval
+		b.reassignMargin = margin
+	}
+}
+
+// reassess moves waiting hall calls to a better car when circumstances have changed since they were assigned:
+// the assigned car has filled up, can no longer be dispatched, or another car can now get there sooner.
+// Calls whose car is already Loading at the landing are being answered and stay where they are.
+func (b *Bank) reassess() {
+	for _, l := range b.landings() {
+		i := b.calls[l]
+		c := b.views[i]
+		if b.isLoadingAt(c, l.floor) {
+			continue
+		}
+
+		switch {
+		case !dispatchable(c):
+			b.move(l, i, "car unavailable")
+		case isFull(c):
+			if next := b.bestCar(l.floor, l.direction, i); next != NoCar && !isFull(b.views[next]) {
+				b.moveTo(l, i, next, "car full")
+			}
+		default:
+			next := b.bestCar(l.floor, l.direction, i)
+			if next == NoCar || isFull(b.views[next]) {
+				continue
+			}
+			saving := b.cost(c, l.floor, l.direction) - b.cost(b.views[next], l.floor, l.direction)
+			if saving > b.reassignMargin.Seconds() {
+				b.moveTo(l, i, next, "sooner car")
+			}
+		}
+	}
+}
+
+// move hands the hall call at the landing from the car at the given index to the next best car,
+// dropping it if there is none. Passengers whose call is dropped will have to call again.
+func (b *Bank) move(l landing, from int, reason string) {
+	next := b.bestCar(l.floor, l.direction, from)
+	if next == NoCar {
+		b.forget(l)
+		b.release(l.floor, from)
+		b.logger.Info("hall call dropped", "floor", l.floor, "direction", l.direction, "car", from, "reason", reason)
+		return
+	}
+	b.moveTo(l, from, next, reason)
+}
+
+// moveTo hands the hall call at the landing to the car at index to.
+func (b *Bank) moveTo(l landing, from, to int, reason string) {
+	if _, err := b.views[to].Call(l.floor); err != nil {
+		b.logger.Error("hall call not sent", "floor", l.floor, "direction", l.direction, "car", to, "error", err)
+		return
+	}
+	b.calls[l] = to
+	b.release(l.floor, from)
+	b.logger.Info("hall call reassigned", "floor", l.floor, "direction", l.direction, "from", from, "to", to, "reason", reason)
+}
+
+// landings returns the landings with hall calls waiting, in floor then direction order,
+// so that calls are re-evaluated in the same order every time.
+func (b *Bank) landings() []landing {
+	landings := make([]landing, 0, len(b.calls))
+	for l := range b.calls {
+		landings = append(landings, l)
+	}
+	slices.SortFunc(landings, func(a, b landing) int {
+		return cmp.Or(cmp.Compare(a.floor, b.floor), cmp.Compare(a.direction, b.direction))
+	})
+	return landings
+}
+
+// isFull reports whether the car has no room for another passenger.
+func isFull(c Member) bool {
+	return c.Load() >= c.Capacity()
+}
@@ -1,35 +1,176 @@
 package stubs
 
-import "github.com/dshaneg/elevator/internal/elevator/car"
+import (
+	"slices"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/elevator/car"
+)
 
 type Car struct {
-	score     int
+	CarScore  time.Duration
+	energy    float64
 	CallCount int
+	TickCount int
+	CarMode   car.Mode
+	Recalled  int // the floor passed to the last Recall
+	CarFault  car.Fault
+	CarLoad   int
+
+	Cancelled    []int // floors passed to CancelCall
+	Pressed      []int // floors passed to Press, less those passed to CancelPress
+	Reopened     bool  // whether Reopen has been called
+	CarFloor     int
+	CarStatus    car.Status
+	CarDoor      car.Door
+	CarDirection car.Direction // Up if unset
+
+	Unreachable []int // floors Reaches reports the car cannot stop at
+	CarFloors   int   // the floors the stub serves, DefaultFloors unless set
+}
+
+// DefaultFloors is the number of floors a stub serves unless set otherwise.
+const DefaultFloors = 5
+
+func NewCar(score time.Duration) *Car {
+	return &Car{
+		CarScore:  score,
+		CarFloors: DefaultFloors,
+	}
 }
 
-func NewCar(score int) *Car {
+// NewCarWithEnergy returns a stub whose energy estimate for every call is the given number of watt hours.
+func NewCarWithEnergy(score time.Duration, energy float64) *Car {
 	return &Car{
-		score: score,
+		CarScore:  score,
+		energy:    energy,
+		CarFloors: DefaultFloors,
 	}
 }
 
-func (c *Car) Score(floor int, direction car.Direction) int {
-	return c.score
+func (c *Car) Score(floor int, direction car.Direction) time.Duration {
+	return c.CarScore
 }
 
-func (c *Car) Call(floor int) []bool {
+func (c *Car) EstimateEnergy(floor int, direction car.Direction) float64 {
+	return c.energy
+}
+
+func (c *Car) Reaches(floor int) bool {
+	return !slices.Contains(c.Unreachable, floor)
+}
+
+func (c *Car) NumFloors() int {
+	return c.CarFloors
+}
+
+func (c *Car) Call(floor int) ([]bool, error) {
+	if !c.Reaches(floor) {
+		return nil, car.ErrFloorOutOfRange
+	}
 	c.CallCount++
-	return []bool{}
+	return []bool{}, nil
+}
+
+func (c *Car) CancelCall(floor int) {
+	c.Cancelled = append(c.Cancelled, floor)
+}
+
+func (c *Car) Press(floor int) ([]bool, error) {
+	c.Pressed = append(c.Pressed, floor)
+	return []bool{}, nil
+}
+
+func (c *Car) CancelPress(floor int) {
+	c.Pressed = slices.DeleteFunc(c.Pressed, func(f int) bool { return f == floor })
+}
+
+// Reopen succeeds for a stub whose status has been set to Loading.
+func (c *Car) Reopen() bool {
+	c.Reopened = true
+	return c.CarStatus == car.Loading
+}
+
+// Calls returns the floors pressed on the stub's buttons.
+func (c *Car) Calls() []int {
+	return slices.Clone(c.Pressed)
 }
 
 func (c *Car) Floor() int {
-	return 0
+	return c.CarFloor
 }
 
 func (c *Car) Direction() car.Direction {
-	return car.Up
+	if c.CarDirection == 0 {
+		return car.Up
+	}
+	return c.CarDirection
 }
 
 func (c *Car) Status() car.Status {
-	return car.Parked
+	return c.CarStatus
 }
+
+func (c *Car) Tick() {
+	c.TickCount++
+}
+
+func (c *Car) Door() car.Door {
+	return c.CarDoor
+}
+
+func (c *Car) Mode() car.Mode {
+	return c.CarMode
+}
+
+func (c *Car) SetMode(mode car.Mode) {
+	c.CarMode = mode
+}
+
+func (c *Car) Recall(floor int) {
+	c.CarMode = car.FireRecall
+	c.Recalled = floor
+}
+
+func (c *Car) FireService() {
+	c.CarMode = car.FireService
+}
+
+func (c *Car) Restore() {
+	c.CarMode = car.Normal
+}
+
+func (c *Car) Fault() car.Fault {
+	return c.CarFault
+}
+
+func (c *Car) Fail(fault car.Fault) {
+	c.CarFault = fault
+}
+
+func (c *Car) Repair() {
+	c.CarFault = car.NoFault
+}
+
+func (c *Car) Load() int {
+	return c.CarLoad
+}
+
+func (c *Car) Capacity() int {
+	return car.DefaultCapacity
+}
+
+func (c *Car) Energy() car.Meter {
+	return car.Meter{}
+}
+
+func (c *Car) Enter() bool {
+	c.CarLoad++
+	return true
+}
+
+func (c *Car) Exit() {
+	c.CarLoad--
+}
+
+func (c *Car) AddStandby(d time.Duration) {}
@@ -1,5 +1,13 @@
 package car
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
 // Direction is an enum type that represents the current direction of the [Car].
 type Direction int
 
@@ -8,6 +16,13 @@ const (
 	Up   Direction = 1
 )
 
+func (d Direction) String() string {
+	if d == Down {
+		return "down"
+	}
+	return "up"
+}
+
 // Status is an enum type that represents the current status of the Car.
 type Status int
 
@@ -17,29 +32,113 @@ const (
 	Traveling
 )
 
+var statusNames = map[Status]string{
+	Parked:    "parked",
+	Loading:   "loading",
+	Traveling: "traveling",
+}
+
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Door is an enum type that represents whether the doors of the Car are open.
+type Door int
+
+const (
+	Closed Door = iota
+	Open
+)
+
+func (d Door) String() string {
+	if d == Open {
+		return "open"
+	}
+	return "closed"
+}
+
+// Car represents a single elevator car. Floors are indexes counting up from 0 at the lowest
+// level served; a floorplan.Plan maps them to the labels passengers see.
+//
+// A Car is safe for concurrent use. Each method sees and leaves the Car in a consistent state, but
+// nothing stops it changing between calls; hold on to a single owner, such as the Bank, to read
+// several things at once.
 type Car struct {
-	buttons   []bool
+	mu sync.Mutex
+
+	buttons   []bool // every floor the Car will stop at
+	pressed   []bool // floors pressed on the Car's own buttons
+	hall      []bool // floors the bank has sent the Car to for hall calls
 	floor     int
 	direction Direction
 	status    Status
+	door      Door
+	mode      Mode
+	resume    Mode // the mode a Car in a fire mode returns to on Restore
+	recall    int  // the floor the Car is recalled to while in FireRecall mode
+	held      bool // whether an attendant has held the doors for the current stop
+	fault     Fault
+	load      int // passengers aboard
+	capacity  int // the most passengers the Car can carry
+	profile   Profile
+	energy    EnergyModel
+	meter     Meter
+	run       int  // floors traveled since the Car last stopped
+	closing   bool // whether the Car closed its doors this step and has yet to leave
+	shaft     *Shaft
+	twin      Twin  // which of the Shaft's cars this is
+	blocked   bool  // whether the Car was held up by its twin this step
+	err       error // why the options given to NewCar are invalid, if they are
 }
 
 // Option is a functional option type that allows us to configure the Car
 type Option func(*Car)
 
-func NewCar(numFloors int, options ...Option) *Car {
+// DefaultCapacity is the number of passengers a Car carries unless configured otherwise.
+const DefaultCapacity = 16
+
+// ErrFloorOutOfRange is returned for a floor the Car does not serve, or cannot reach.
+var ErrFloorOutOfRange = errors.New("car: floor out of range")
+
+// NewCar creates a Car serving the given number of floors, parked at floor 0 unless configured otherwise.
+// It returns an error wrapping ErrFloorOutOfRange if there are no floors, if a floor given in the options
+// is not one the Car can reach, or if the Car's Shaft does not fit its floors or leaves it too close to its twin.
+func NewCar(numFloors int, options ...Option) (*Car, error) {
+	if numFloors < 1 {
+		return nil, fmt.Errorf("%w: a Car must serve at least one floor, not %d", ErrFloorOutOfRange, numFloors)
+	}
 	car := Car{
 		buttons:   make([]bool, numFloors),
+		pressed:   make([]bool, numFloors),
+		hall:      make([]bool, numFloors),
 		floor:     0,
 		direction: Up,
 		status:    Parked,
+		capacity:  DefaultCapacity,
+		profile:   DefaultProfile,
+		energy:    DefaultEnergyModel,
 	}
 
 	for _, opt := range options {
 		opt(&car)
 	}
+	if car.err != nil {
+		return nil, car.err
+	}
+	if err := car.checkFloor(car.floor); err != nil {
+		return nil, err
+	}
+	if car.shaft != nil {
+		if err := car.shaft.check(car.twin, numFloors, car.floor); err != nil {
+			return nil, err
+		}
+		car.shaft.join(car.twin, car.floor)
+	}
 
-	return &car
+	return &car, nil
 }
 
 // WithFloor is a functional option that sets the current floor of the Car.
@@ -57,34 +156,112 @@ func WithDirection(direction Direction) Option {
 }
 
 // WithStatus is a functional option that sets the current status of the Car.
+// A Car that is Loading has its doors open.
 func WithStatus(status Status) Option {
 	return func(c *Car) {
 		c.status = status
+		if status == Loading {
+			c.door = Open
+		}
 	}
 }
 
-// WithCalls is a functional option that sets the current calls of the Car.
+// WithCapacity is a functional option that sets the most passengers the Car can carry.
+func WithCapacity(capacity int) Option {
+	return func(c *Car) {
+		c.capacity = capacity
+	}
+}
+
+// WithCalls is a functional option that sets the current calls of the Car, as though pressed on its buttons.
 func WithCalls(calls []int) Option {
 	return func(c *Car) {
 		for _, floor := range calls {
+			if floor < 0 || floor >= len(c.buttons) {
+				c.err = errors.Join(c.err, fmt.Errorf("%w: call to %d is not one of the %d floors served", ErrFloorOutOfRange, floor, len(c.buttons)))
+				continue
+			}
+			c.pressed[floor] = true
 			c.buttons[floor] = true
 		}
 	}
 }
 
+// NumFloors returns the number of floors the Car serves.
+func (c *Car) NumFloors() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.buttons)
+}
+
 func (c *Car) Floor() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.floor
 }
 
 func (c *Car) Direction() Direction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.direction
 }
 
 func (c *Car) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.status
 }
 
+// Door returns whether the doors of the Car are open or closed.
+func (c *Car) Door() Door {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.door
+}
+
+// Load returns the number of passengers aboard the Car.
+func (c *Car) Load() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.load
+}
+
+// Capacity returns the most passengers the Car can carry.
+func (c *Car) Capacity() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.capacity
+}
+
+// Enter boards a passenger, returning false if the Car is full.
+func (c *Car) Enter() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.load >= c.capacity {
+		return false
+	}
+	c.load++
+	c.meter.Passengers++
+	return true
+}
+
+// Exit lets a passenger off the Car.
+func (c *Car) Exit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.load > 0 {
+		c.load--
+	}
+}
+
+// Calls returns the floors the Car will stop at, in order.
 func (c *Car) Calls() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls()
+}
+
+func (c *Car) calls() []int {
 	calls := []int{}
 	for floor, called := range c.buttons {
 		if called {
@@ -94,31 +271,124 @@ func (c *Car) Calls() []int {
 	return calls
 }
 
+// Tick advances the Car by one step. A Car that is Loading spends the step closing its doors
+// (unless it has been called to the floor again), otherwise it moves one floor toward its next
+// call and starts Loading when it arrives.
 func (c *Car) Tick() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	floor, door := c.floor, c.door
+	c.tick()
+	c.meterTick(floor, door)
+	if c.shaft != nil {
+		c.shaft.report(c.twin, c.floor, c.blocked)
+	}
+}
+
+func (c *Car) tick() {
+	c.closing = false
+	c.blocked = false
+	if c.fault != NoFault {
+		return
+	}
+
+	switch c.mode {
+	case FireRecall:
+		c.tickRecall()
+		return
+	case FireService:
+		if c.door == Open {
+			// the firefighter has to close the doors before the car will move
+			return
+		}
+	case Attendant:
+		if c.status == Loading && !c.held {
+			// the attendant holds the doors for an extra step while passengers board
+			c.held = true
+			return
+		}
+	}
+
+	if c.status == Loading {
+		if c.buttons[c.floor] {
+			// called to this floor while loading, so hold the doors open
+			c.clearCall(c.floor)
+			return
+		}
+		c.status = c.restingStatus()
+		c.door = Closed
+		c.held = false
+		c.closing = true
+		return
+	}
+
+	if c.mode == Inspection {
+		// only the technician moves the car
+		c.status = Parked
+		return
+	}
+
 	targetFloor := c.calculateTargetFloor()
+	if targetFloor == c.floor && !c.buttons[c.floor] {
+		c.status = Parked
+		c.giveWay(true, false)
+		return
+	}
 
 	c.updateDirection(targetFloor)
-	c.updateFloor(targetFloor)
+	if !c.updateFloor(targetFloor) {
+		// held up by its twin
+		c.status = Traveling
+		c.blocked = true
+		c.giveWay(false, true)
+		return
+	}
 
-	if targetFloor == c.floor {
-		c.clearCall(targetFloor)
+	if targetFloor != c.floor {
+		c.status = Traveling
+		return
+	}
 
-		targetFloor = c.calculateTargetFloor()
-		c.updateDirection(targetFloor)
+	c.clearCall(targetFloor)
+	if c.mode == FireService {
+		// arrive with the doors closed and wait for the firefighter to open them
+		c.status = Parked
+	} else {
+		c.status = Loading
+		c.door = Open
 	}
 
+	targetFloor = c.calculateTargetFloor()
+	c.updateDirection(targetFloor)
+}
+
+// restingStatus returns Traveling if the Car has somewhere to go, otherwise Parked.
+func (c *Car) restingStatus() Status {
+	if c.countStops() > 0 {
+		return Traveling
+	}
+	return Parked
 }
 
 func (c *Car) clearCall(floor int) {
 	c.buttons[floor] = false
+	c.pressed[floor] = false
+	c.hall[floor] = false
 }
 
-func (c *Car) updateFloor(targetFloor int) {
+// updateFloor moves the Car one floor toward the target floor, returning false if its twin is in the way.
+func (c *Car) updateFloor(targetFloor int) bool {
+	next := c.floor
 	if targetFloor > c.floor {
-		c.floor++
+		next++
 	} else if targetFloor < c.floor {
-		c.floor--
+		next--
+	}
+	if next != c.floor && c.shaft != nil && !c.shaft.clear(c.twin, next) {
+		return false
 	}
+	c.floor = next
+	return true
 }
 
 func (c *Car) updateDirection(targetFloor int) {
@@ -175,60 +445,157 @@ func (c *Car) findNextUpCall() (target int, found bool) {
 	return 0, false
 }
 
-func (c *Car) Call(floor int) []bool {
-	c.buttons[floor] = true
-	return c.buttons
+// Call sends the Car to the given floor to answer a hall call, returning the floors it will stop at.
+// Calls are ignored unless the Car is accepting them, see [Mode], and Call returns an error wrapping
+// ErrFloorOutOfRange for a floor the Car cannot reach; see [Car.Reaches].
+func (c *Car) Call(floor int) ([]bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.checkFloor(floor); err != nil {
+		return nil, err
+	}
+	if c.mode.acceptsCalls() {
+		c.hall[floor] = true
+		c.buttons[floor] = true
+	}
+	return slices.Clone(c.buttons), nil
 }
 
-func (c *Car) Score(floor int, direction Direction) int {
-	distance := c.findDistance(floor)
-	stops := c.countStops()
+// CancelCall takes back a hall call sent with [Car.Call]. The Car still stops at the floor if a passenger
+// has pressed it.
+func (c *Car) CancelCall(floor int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if floor < 0 || floor >= len(c.buttons) {
+		return
+	}
+	c.hall[floor] = false
+	c.buttons[floor] = c.pressed[floor]
+}
 
-	return distance + stops*5
+// Press presses the Car's own button for the given floor, as a passenger aboard does, returning the floors
+// the Car will stop at. Presses are ignored unless the Car is accepting calls, see [Mode], and Press returns
+// an error wrapping ErrFloorOutOfRange for a floor the Car cannot reach.
+func (c *Car) Press(floor int) ([]bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.checkFloor(floor); err != nil {
+		return nil, err
+	}
+	if c.mode.acceptsCalls() {
+		c.pressed[floor] = true
+		c.buttons[floor] = true
+	}
+	return slices.Clone(c.buttons), nil
 }
 
-func (c *Car) countStops() int {
-	stops := 0
-	for _, pressed := range c.buttons {
-		if pressed {
-			stops++
-		}
+// CancelPress cancels the car call for the given floor, as a passenger does by pressing its lit button
+// twice in quick succession. The Car still stops at the floor if it has been sent there for a hall call.
+func (c *Car) CancelPress(floor int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if floor < 0 || floor >= len(c.buttons) {
+		return
 	}
-	return stops
+	c.pressed[floor] = false
+	c.buttons[floor] = c.hall[floor]
 }
 
-func (c *Car) findDistance(floor int) int {
-	distance := 0
-	switch {
-	case floor < c.floor:
-		if c.direction == Up {
-			distance += (c.topStop() - c.floor) * 2
+// Reopen opens the doors again for a late passenger, returning true if the Car is at its stop with
+// its doors open. Doors can only be reopened while they are open or in the step they closed, before
+// the Car has left, and not at all while the Car is broken down or under fire service.
+func (c *Car) Reopen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fault != NoFault || c.mode == FireService || !c.mode.acceptsCalls() {
+		return false
+	}
+	if c.status == Loading {
+		return true
+	}
+	if !c.closing {
+		return false
+	}
+
+	c.closing = false
+	c.status = Loading
+	c.door = Open
+	c.meterDoors()
+	return true
+}
+
+// Score estimates how long the Car will take to arrive at the given floor, ready to carry a passenger
+// in the given direction. The estimate follows the Car's committed stops, sweeping in its current
+// direction before turning back, and uses its motion Profile for the runs and stops along the way.
+func (c *Car) Score(floor int, direction Direction) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var eta time.Duration
+	if c.status == Loading {
+		if floor == c.floor && (direction == c.direction || c.countStops() == 0) {
+			return 0
+		}
+		eta += c.profile.DoorTime / 2
+	} else if c.buttons[c.floor] && floor != c.floor {
+		// called to the floor it is standing at, so it opens its doors before going anywhere
+		eta += c.profile.StopTime()
+	}
+
+	pos, dir := c.floor, c.direction
+	stops := c.calls()
+
+	// a car's path sweeps one way, then back the other way, then back again, so the call
+	// is answered within three sweeps
+	for range 3 {
+		ahead := stopsAhead(stops, pos, dir)
+
+		if isAhead(pos, floor, dir) && (direction == dir || len(stopsAhead(stops, floor, dir)) == 0) {
+			for _, stop := range ahead {
+				if !isAhead(stop, floor, dir) || stop == floor {
+					break
+				}
+				eta += c.profile.RunTime(abs(stop-pos)) + c.profile.StopTime()
+				pos = stop
+			}
+			return eta + c.profile.RunTime(abs(floor-pos))
 		}
-		distance += c.floor - floor
-	case floor > c.floor:
-		if c.direction == Down {
-			distance += (c.floor - c.bottomStop()) * 2
+
+		for _, stop := range ahead {
+			eta += c.profile.RunTime(abs(stop-pos)) + c.profile.StopTime()
+			pos = stop
+			stops = slices.DeleteFunc(stops, func(s int) bool { return s == stop })
 		}
-		distance += floor - c.floor
+		dir = -dir
 	}
 
-	return distance
+	return eta + c.profile.RunTime(abs(floor-pos))
 }
 
-func (c *Car) bottomStop() int {
-	for i, pressed := range c.buttons {
-		if pressed {
-			return i
+// stopsAhead returns the stops beyond the given floor in the given direction, nearest first.
+func stopsAhead(stops []int, floor int, dir Direction) []int {
+	ahead := []int{}
+	for _, stop := range stops {
+		if stop != floor && isAhead(floor, stop, dir) {
+			ahead = append(ahead, stop)
 		}
 	}
-	return 0
+	if dir == Down {
+		slices.Reverse(ahead)
+	}
+	return ahead
 }
 
-func (c *Car) topStop() int {
-	for i := len(c.buttons) - 1; i >= 0; i-- {
-		if c.buttons[i] {
-			return i
+// isAhead reports whether a car at one floor heading in the given direction reaches the other floor without turning.
+func isAhead(from, to int, dir Direction) bool {
+	return (to-from)*int(dir) >= 0
+}
+
+func (c *Car) countStops() int {
+	stops := 0
+	for _, pressed := range c.buttons {
+		if pressed {
+			stops++
 		}
 	}
-	return len(c.buttons) - 1
+	return stops
 }
@@ -1,14 +1,50 @@
 package car_test
 
 import (
+	"math/rand/v2"
 	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/dshaneg/elevator/internal/elevator/car"
 )
 
+// newCar creates a Car, failing the test if the options are invalid.
+func newCar(t *testing.T, numFloors int, options ...car.Option) *car.Car {
+	t.Helper()
+	c, err := car.NewCar(numFloors, options...)
+	require.NoError(t, err)
+	return c
+}
+
+func newDoubleDeck(t *testing.T, numFloors, lobby int, options ...car.Option) *car.DoubleDeck {
+	t.Helper()
+	d, err := car.NewDoubleDeck(numFloors, lobby, options...)
+	require.NoError(t, err)
+	return d
+}
+
+func newShaft(t *testing.T, lowest, highest, separation int) *car.Shaft {
+	t.Helper()
+	s, err := car.NewShaft(lowest, highest, separation)
+	require.NoError(t, err)
+	return s
+}
+
+// scoreProfile makes the arithmetic in scoreCases easy to follow: a run of n floors takes 2n+1 seconds
+// (2m floors at 1m/s, plus a second lost speeding up and slowing down) and each stop takes 5 seconds.
+var scoreProfile = car.Profile{
+	FloorHeight:  2,
+	Speed:        1,
+	Acceleration: 1,
+	DoorTime:     4 * time.Second,
+	Dwell:        time.Second,
+}
+
 var scoreCases = []struct {
 	name             string
 	numFloors        int
@@ -18,74 +54,65 @@ var scoreCases = []struct {
 	currentStatus    car.Status
 	callFloor        int
 	callDirection    car.Direction
-	expected         int
+	expected         time.Duration
 }{
 	{
-		// 0 -> 1
-		name:             "No calls on ground floor, call to 1 expect score 1",
+		// a 1 floor run
+		name:             "No calls on ground floor, call to 1 expect 3s",
 		numFloors:        5,
 		currentCalls:     []int{},
 		currentFloor:     0,
 		currentDirection: car.Up,
 		callFloor:        1,
 		callDirection:    car.Up,
-		expected:         1,
+		expected:         3 * time.Second,
 	},
 	{
-		// 0 -> 1 -> 2
-		name:             "No calls on ground floor, call to 2 expect score 2",
+		// a 2 floor run
+		name:             "No calls on ground floor, call to 2 expect 5s",
 		numFloors:        5,
 		currentCalls:     []int{},
 		currentFloor:     0,
 		currentDirection: car.Up,
 		callFloor:        2,
 		callDirection:    car.Up,
-		expected:         2,
+		expected:         5 * time.Second,
 	},
 	{
-		// distance between current and call floor (in floors) + 5*number of stops
-		// 0 -> 1 -> 2 -> 3 (3 floors for a subscore of 3)
-		// plus stopping on 2, so 5 * 1 stop
-		// gives a score of 8
-		name:             "On ground floor, 2 already called, call to 3 expect score 8",
+		// 0 -> 2 (5s), stop at 2 (5s), 2 -> 3 (3s)
+		name:             "On ground floor, 2 already called, call to 3 expect 13s",
 		numFloors:        5,
 		currentCalls:     []int{2},
 		currentFloor:     0,
 		currentDirection: car.Up,
 		callFloor:        3,
 		callDirection:    car.Up,
-		expected:         8,
+		expected:         13 * time.Second,
 	},
 	{
-		// 2 -> 3 -> 4 -> 3 -> 2 -> 1 -> 0 (traverse 6 floors for a subscore of 6)
-		// plus stopping on 4, so 5 * 1 stop
-		// gives a score of 11
-		name:             "On floor 2, 4 already called, call to 0(ground) expect score 11",
+		// 2 -> 4 (5s), stop at 4 (5s), 4 -> 0 (9s)
+		name:             "On floor 2, 4 already called, call to 0(ground) expect 19s",
 		numFloors:        5,
 		currentCalls:     []int{4},
 		currentFloor:     2,
 		currentDirection: car.Up,
 		callFloor:        0,
-		callDirection:    car.Down, // irrelevant so far
-		expected:         11,
+		callDirection:    car.Down,
+		expected:         19 * time.Second,
 	},
 	{
-		// 2 -> 1 -> 0 -> 1 -> 2 -> 3 -> 4 (traverse 6 floors for a subscore of 6)
-		// plus stopping on 4, so 5 * 1 stop
-		// gives a score of 11
-		name:             "On floor 2, 0(ground) already called, call to 4(top) expect score 11",
+		// 2 -> 0 (5s), stop at 0 (5s), 0 -> 4 (9s), where the car turns to go down
+		name:             "On floor 2, 0(ground) already called, call to 4(top) expect 19s",
 		numFloors:        5,
 		currentCalls:     []int{0},
 		currentFloor:     2,
 		currentDirection: car.Down,
 		callFloor:        4,
 		callDirection:    car.Down,
-		expected:         11,
+		expected:         19 * time.Second,
 	},
 	{
-		// 2 -> 2 (traverse 0 floors for a subscore of 0)
-		// gives a score of 0
-		name:             "On floor 2, no calls, call to 2(current floor) expect score 0",
+		name:             "On floor 2, no calls, call to 2(current floor) expect 0s",
 		numFloors:        5,
 		currentCalls:     []int{},
 		currentFloor:     2,
@@ -94,16 +121,63 @@ var scoreCases = []struct {
 		callDirection:    car.Down,
 		expected:         0,
 	},
+	{
+		// the car passes 3 on its way up to 4 but can't take a passenger down until it comes back:
+		// 1 -> 4 (7s), stop at 4 (5s), 4 -> 3 (3s)
+		name:             "On floor 1 going up to 4, call to 3 going down expect 15s",
+		numFloors:        5,
+		currentCalls:     []int{4},
+		currentFloor:     1,
+		currentDirection: car.Up,
+		callFloor:        3,
+		callDirection:    car.Down,
+		expected:         15 * time.Second,
+	},
+	{
+		// the same call going up is on the way: 1 -> 3 (5s)
+		name:             "On floor 1 going up to 4, call to 3 going up expect 5s",
+		numFloors:        5,
+		currentCalls:     []int{4},
+		currentFloor:     1,
+		currentDirection: car.Up,
+		callFloor:        3,
+		callDirection:    car.Up,
+		expected:         5 * time.Second,
+	},
+	{
+		// the doors have to close before the car can leave: 2s, then 2 -> 3 (3s)
+		name:             "Loading on floor 2, call to 3 expect 5s",
+		numFloors:        5,
+		currentCalls:     []int{},
+		currentFloor:     2,
+		currentDirection: car.Up,
+		currentStatus:    car.Loading,
+		callFloor:        3,
+		callDirection:    car.Up,
+		expected:         5 * time.Second,
+	},
+	{
+		// called to the floor it is parked at, so it stops there first: 5s, then 0 -> 2 (5s)
+		name:             "On ground floor, 0 already called, call to 2 expect 10s",
+		numFloors:        5,
+		currentCalls:     []int{0},
+		currentFloor:     0,
+		currentDirection: car.Up,
+		callFloor:        2,
+		callDirection:    car.Down,
+		expected:         10 * time.Second,
+	},
 }
 
 func TestScore(t *testing.T) {
 	for _, tc := range scoreCases {
 		t.Run(tc.name, func(t *testing.T) {
-			c := car.NewCar(tc.numFloors,
+			c := newCar(t, tc.numFloors,
 				car.WithFloor(tc.currentFloor),
 				car.WithDirection(tc.currentDirection),
 				car.WithStatus(tc.currentStatus),
 				car.WithCalls(tc.currentCalls),
+				car.WithProfile(scoreProfile),
 			)
 
 			got := c.Score(tc.callFloor, tc.callDirection)
@@ -112,6 +186,16 @@ func TestScore(t *testing.T) {
 	}
 }
 
+func TestScoreDefaultProfile(t *testing.T) {
+	c := newCar(t, 20)
+
+	// a car that can't reach rated speed in a single floor is slower per floor over short runs
+	short, long := c.Score(1, car.Up), c.Score(10, car.Up)
+	assert.Greater(t, short, time.Duration(0))
+	assert.Greater(t, long, 10*time.Duration(float64(time.Second)*car.DefaultProfile.FloorHeight/car.DefaultProfile.Speed))
+	assert.Greater(t, short*10, long)
+}
+
 var tickCases = []struct {
 	name              string
 	numFloors         int
@@ -145,6 +229,7 @@ var tickCases = []struct {
 		expectedFloor:     3,
 		expectedDirection: car.Up,
 		expectedCalls:     []int{},
+		expectedStatus:    car.Loading,
 	},
 	{
 		// 2 -> 3
@@ -156,6 +241,7 @@ var tickCases = []struct {
 		expectedFloor:     3,
 		expectedDirection: car.Up,
 		expectedCalls:     []int{},
+		expectedStatus:    car.Loading,
 	},
 	{
 		name:              "On floor 2, request floor 0, floor 4 already queued",
@@ -166,6 +252,7 @@ var tickCases = []struct {
 		expectedFloor:     3,
 		expectedDirection: car.Up,
 		expectedCalls:     []int{0, 4},
+		expectedStatus:    car.Traveling,
 	},
 	{
 		name:              "On floor 3, request floor 0, floor 4 already queued",
@@ -176,6 +263,7 @@ var tickCases = []struct {
 		expectedFloor:     4,
 		expectedDirection: car.Down,
 		expectedCalls:     []int{0},
+		expectedStatus:    car.Loading,
 	},
 	{
 		name:              "On floor 1, request floor 4, floor 0 already queued",
@@ -186,13 +274,49 @@ var tickCases = []struct {
 		expectedFloor:     0,
 		expectedDirection: car.Up,
 		expectedCalls:     []int{4},
+		expectedStatus:    car.Loading,
+	},
+	{
+		name:              "Loading on floor 2 with floor 4 queued, expect doors to close without moving",
+		numFloors:         5,
+		currentCalls:      []int{4},
+		currentFloor:      2,
+		currentDirection:  car.Up,
+		currentStatus:     car.Loading,
+		expectedFloor:     2,
+		expectedDirection: car.Up,
+		expectedCalls:     []int{4},
+		expectedStatus:    car.Traveling,
+	},
+	{
+		name:              "Loading on floor 2 with no calls, expect to park",
+		numFloors:         5,
+		currentCalls:      []int{},
+		currentFloor:      2,
+		currentDirection:  car.Up,
+		currentStatus:     car.Loading,
+		expectedFloor:     2,
+		expectedDirection: car.Up,
+		expectedCalls:     []int{},
+		expectedStatus:    car.Parked,
+	},
+	{
+		name:              "Parked on floor 2 with a call to floor 2, expect to load",
+		numFloors:         5,
+		currentCalls:      []int{2},
+		currentFloor:      2,
+		currentDirection:  car.Down,
+		expectedFloor:     2,
+		expectedDirection: car.Down,
+		expectedCalls:     []int{},
+		expectedStatus:    car.Loading,
 	},
 }
 
 func TestTick(t *testing.T) {
 	for _, tc := range tickCases {
 		t.Run(tc.name, func(t *testing.T) {
-			c := car.NewCar(tc.numFloors,
+			c := newCar(t, tc.numFloors,
 				car.WithFloor(tc.currentFloor),
 				car.WithDirection(tc.currentDirection),
 				car.WithStatus(tc.currentStatus),
@@ -203,6 +327,7 @@ func TestTick(t *testing.T) {
 
 			assert.Equal(t, tc.expectedFloor, c.Floor())
 			assert.Equal(t, tc.expectedDirection, c.Direction())
+			assert.Equal(t, tc.expectedStatus, c.Status())
 			calls := c.Calls()
 			assert.True(t, equalUnsorted(tc.expectedCalls, calls))
 		})
@@ -217,8 +342,631 @@ func equalUnsorted(s1, s2 []int) bool {
 
 func TestCallFloorSetsCallButton(t *testing.T) {
 	const numFloors = 10
-	c := car.NewCar(numFloors)
-	calls := c.Call(2)
+	c := newCar(t, numFloors)
+	calls, err := c.Call(2)
+	require.NoError(t, err)
 	expected := []bool{false, false, true, false, false, false, false, false, false, false}
 	assert.Equal(t, expected, calls)
 }
+
+func TestRecallTravelsNonStopAndOpensDoors(t *testing.T) {
+	c := newCar(t, 10,
+		car.WithFloor(5),
+		car.WithDirection(car.Up),
+		car.WithStatus(car.Loading),
+		car.WithCalls([]int{7, 8}),
+	)
+
+	c.Recall(2)
+	assert.Equal(t, car.FireRecall, c.Mode())
+	assert.Equal(t, car.Closed, c.Door())
+	assert.Empty(t, c.Calls())
+
+	// car calls are ignored on the way down
+	c.Call(4)
+	assert.Empty(t, c.Calls())
+
+	for range 3 {
+		c.Tick()
+		assert.Equal(t, car.Closed, c.Door())
+	}
+	assert.Equal(t, 2, c.Floor())
+
+	c.Tick()
+	assert.Equal(t, 2, c.Floor())
+	assert.Equal(t, car.Parked, c.Status())
+	assert.Equal(t, car.Open, c.Door())
+
+	// and it stays put
+	c.Tick()
+	assert.Equal(t, 2, c.Floor())
+	assert.Equal(t, car.Open, c.Door())
+}
+
+func TestFireServiceWaitsForFirefighter(t *testing.T) {
+	c := newCar(t, 10, car.WithFloor(2))
+	c.Recall(2)
+	c.Tick()
+	c.FireService()
+
+	c.Call(4)
+	c.Tick()
+	assert.Equal(t, 2, c.Floor(), "doors left open from the recall must be closed first")
+
+	c.CloseDoors()
+	c.Tick()
+	c.Tick()
+	assert.Equal(t, 4, c.Floor())
+	assert.Equal(t, car.Parked, c.Status())
+	assert.Equal(t, car.Closed, c.Door())
+
+	c.OpenDoors()
+	assert.Equal(t, car.Open, c.Door())
+
+	c.Restore()
+	assert.Equal(t, car.Normal, c.Mode())
+	assert.Equal(t, car.Closed, c.Door())
+	assert.Equal(t, car.Parked, c.Status())
+}
+
+func TestSetMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          car.Mode
+		expectedFloor int
+		expectedCalls []int
+		dispatchable  bool
+	}{
+		{
+			name:          "Out of service finishes its calls but takes no more",
+			mode:          car.OutOfService,
+			expectedFloor: 4,
+			expectedCalls: []int{},
+		},
+		{
+			name:          "Inspection stops where it is",
+			mode:          car.Inspection,
+			expectedFloor: 1,
+			expectedCalls: []int{},
+		},
+		{
+			name:          "Independent service answers car calls",
+			mode:          car.Independent,
+			expectedFloor: 4,
+			expectedCalls: []int{0},
+		},
+		{
+			name:          "Attendant service answers car calls",
+			mode:          car.Attendant,
+			expectedFloor: 4,
+			expectedCalls: []int{0},
+			dispatchable:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newCar(t, 5, car.WithFloor(1), car.WithCalls([]int{4}))
+			c.SetMode(tc.mode)
+			c.Call(0)
+
+			for range 3 {
+				c.Tick()
+			}
+
+			assert.Equal(t, tc.mode, c.Mode())
+			assert.Equal(t, tc.dispatchable, c.Mode().Dispatchable())
+			assert.Equal(t, tc.expectedFloor, c.Floor())
+			assert.Equal(t, tc.expectedCalls, c.Calls())
+		})
+	}
+}
+
+func TestAttendantHoldsDoors(t *testing.T) {
+	c := newCar(t, 5, car.WithFloor(2), car.WithStatus(car.Loading), car.WithCalls([]int{4}))
+	c.SetMode(car.Attendant)
+
+	c.Tick()
+	assert.Equal(t, car.Loading, c.Status())
+	assert.Equal(t, car.Open, c.Door())
+
+	c.Tick()
+	assert.Equal(t, car.Traveling, c.Status())
+	assert.Equal(t, car.Closed, c.Door())
+}
+
+func TestSetModeIgnoresFireModes(t *testing.T) {
+	c := newCar(t, 5)
+	c.SetMode(car.FireRecall)
+	assert.Equal(t, car.Normal, c.Mode())
+
+	c.Recall(0)
+	c.SetMode(car.OutOfService)
+	assert.Equal(t, car.FireRecall, c.Mode())
+
+	c.Restore()
+	assert.Equal(t, car.OutOfService, c.Mode(), "switched once restored")
+}
+
+func TestRestoreReturnsToTheModeBeforeRecall(t *testing.T) {
+	for _, mode := range []car.Mode{car.Normal, car.OutOfService, car.Inspection, car.Independent, car.Attendant} {
+		t.Run(mode.String(), func(t *testing.T) {
+			c := newCar(t, 5, car.WithFloor(3))
+			c.SetMode(mode)
+
+			c.Recall(0)
+			c.Tick()
+			c.FireService()
+			c.Recall(0)
+			c.Restore()
+			assert.Equal(t, mode, c.Mode())
+
+			c.Restore()
+			assert.Equal(t, mode, c.Mode(), "a Car not in a fire mode is left as it is")
+		})
+	}
+}
+
+func TestFailStopsCarUntilRepaired(t *testing.T) {
+	tests := []struct {
+		name           string
+		fault          car.Fault
+		expectedStatus car.Status
+	}{
+		{name: "Door fault", fault: car.DoorFault, expectedStatus: car.Parked},
+		{name: "Stall", fault: car.Stall, expectedStatus: car.Traveling},
+		{name: "Levelling error", fault: car.LevellingError, expectedStatus: car.Parked},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newCar(t, 5, car.WithFloor(1), car.WithStatus(car.Loading), car.WithCalls([]int{3}))
+
+			c.Fail(tc.fault)
+			c.Tick()
+			assert.Equal(t, tc.fault, c.Fault())
+			assert.Equal(t, 1, c.Floor())
+			assert.Equal(t, tc.expectedStatus, c.Status())
+			assert.Equal(t, car.Closed, c.Door())
+
+			c.Repair()
+			c.Tick()
+			c.Tick()
+			assert.Equal(t, car.NoFault, c.Fault())
+			assert.Equal(t, 3, c.Floor())
+		})
+	}
+}
+
+func TestCancelPress(t *testing.T) {
+	c := newCar(t, 5)
+
+	c.Press(3)
+	c.Press(4)
+	c.CancelPress(3)
+	assert.Equal(t, []int{4}, c.Calls())
+
+	// a stop for a hall call survives a passenger changing their mind
+	c.Call(2)
+	c.Press(2)
+	c.CancelPress(2)
+	assert.Equal(t, []int{2, 4}, c.Calls())
+}
+
+func TestCancelCall(t *testing.T) {
+	c := newCar(t, 5)
+
+	c.Call(3)
+	c.CancelCall(3)
+	assert.Empty(t, c.Calls())
+
+	// a stop a passenger pressed for survives the hall call being cancelled
+	c.Press(2)
+	c.Call(2)
+	c.CancelCall(2)
+	assert.Equal(t, []int{2}, c.Calls())
+}
+
+func TestReopen(t *testing.T) {
+	c := newCar(t, 5, car.WithFloor(1), car.WithStatus(car.Loading), car.WithCalls([]int{3}))
+	assert.True(t, c.Reopen(), "doors that are open stay open")
+
+	// the doors close, and a late passenger catches them before the car leaves
+	c.Tick()
+	assert.Equal(t, car.Closed, c.Door())
+	assert.True(t, c.Reopen())
+	assert.Equal(t, car.Open, c.Door())
+	assert.Equal(t, car.Loading, c.Status())
+
+	// once the car is on its way it is too late
+	c.Tick()
+	c.Tick()
+	assert.Equal(t, 2, c.Floor())
+	assert.False(t, c.Reopen())
+	assert.Equal(t, car.Closed, c.Door())
+}
+
+func TestReopenRefusedOutOfNormalService(t *testing.T) {
+	c := newCar(t, 5, car.WithStatus(car.Loading))
+	c.Fail(car.DoorFault)
+	assert.False(t, c.Reopen())
+
+	c = newCar(t, 5, car.WithStatus(car.Loading))
+	c.Tick()
+	c.SetMode(car.OutOfService)
+	assert.False(t, c.Reopen())
+}
+
+// TestConcurrentUse ticks a Car while other goroutines call it, press its buttons and read its state.
+// It is meant to be run with the race detector.
+func TestConcurrentUse(t *testing.T) {
+	const floors, steps = 10, 200
+	c := newCar(t, floors)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for range steps {
+			c.Tick()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := range steps {
+			c.Call(i % floors)
+			c.Press((i * 3) % floors)
+			c.CancelPress((i * 7) % floors)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := range steps {
+			c.Score(i%floors, car.Up)
+			c.EstimateEnergy(i%floors, car.Down)
+			floor := c.Floor()
+			assert.True(t, floor >= 0 && floor < floors)
+			c.Calls()
+			c.Energy()
+		}
+	}()
+	wg.Wait()
+
+	for range 4 * floors {
+		c.Tick()
+	}
+	assert.Empty(t, c.Calls())
+}
+
+func TestDoubleDeck(t *testing.T) {
+	d := newDoubleDeck(t, 10, 0, car.WithCapacity(2))
+	lower, upper := d.Decks()[0], d.Decks()[1]
+	assert.Equal(t, 0, lower.Floor())
+	assert.Equal(t, 1, upper.Floor())
+	assert.Same(t, lower, d.DeckFor(6))
+	assert.Same(t, upper, d.DeckFor(7))
+
+	assert.Equal(t, d.Score(4, car.Up), d.Score(5, car.Up), "both decks stop together")
+	assert.Less(t, d.Score(4, car.Up), d.Score(6, car.Up))
+
+	d.Press(4)
+	d.Press(5)
+	assert.Equal(t, []int{4, 5}, d.Calls(), "one stop answers both floors")
+	assert.Equal(t, []int{4}, lower.Calls())
+	assert.Equal(t, []int{5}, upper.Calls())
+
+	assert.True(t, upper.Enter())
+	assert.True(t, upper.Enter())
+	assert.False(t, upper.Enter(), "the upper deck is full")
+	assert.True(t, d.Enter(), "the lower deck has room")
+	assert.Equal(t, 3, d.Load())
+	assert.Equal(t, 1, lower.Load())
+
+	for range 20 {
+		if d.Status() == car.Loading && d.Floor() == 4 {
+			break
+		}
+		d.Tick()
+	}
+	assert.Equal(t, car.Loading, d.Status())
+	assert.Equal(t, 4, lower.Floor())
+	assert.Equal(t, 5, upper.Floor())
+
+	upper.Exit()
+	upper.Exit()
+	upper.Exit()
+	assert.Equal(t, 0, upper.Load(), "no one is left to get off")
+	assert.Equal(t, 1, d.Load())
+}
+
+func TestDoubleDeckButtonsAreByFloor(t *testing.T) {
+	d := newDoubleDeck(t, 6, 0)
+	lower, upper := d.Decks()[0], d.Decks()[1]
+
+	buttons, err := d.Call(3)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, false, true, true, false, false}, buttons, "the lower deck stops at 2 as the upper stops at 3")
+
+	buttons, err = lower.Call(4)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, false, true, false, true, false}, buttons)
+
+	buttons, err = upper.Press(1)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true, false, true, false, true}, buttons)
+
+	buttons, err = d.Press(0)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true, true, true, true, true}, buttons)
+}
+
+func TestDoubleDeckStopsOutOfStep(t *testing.T) {
+	d := newDoubleDeck(t, 10, 0)
+	lower := d.Decks()[0]
+
+	lower.Press(5)
+	for range 20 {
+		if lower.Floor() == 5 && d.Status() == car.Loading {
+			break
+		}
+		d.Tick()
+	}
+	assert.Equal(t, 5, lower.Floor())
+	assert.Equal(t, 6, d.Decks()[1].Floor())
+}
+
+func TestTwinsReach(t *testing.T) {
+	s := newShaft(t, 0, 9, 2)
+	lower := newCar(t, 10, car.WithShaft(s, car.LowerTwin))
+	upper := newCar(t, 10, car.WithShaft(s, car.UpperTwin), car.WithFloor(9))
+
+	assert.True(t, lower.Reaches(7))
+	assert.False(t, lower.Reaches(8))
+	assert.False(t, upper.Reaches(1))
+	assert.True(t, upper.Reaches(2))
+	assert.True(t, newCar(t, 10).Reaches(9))
+	assert.False(t, newCar(t, 10).Reaches(10))
+
+	_, err := lower.Press(8)
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange)
+	_, err = upper.Call(1)
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange)
+	assert.Empty(t, lower.Calls(), "out of reach")
+	assert.Empty(t, upper.Calls(), "out of reach")
+}
+
+func TestShaftErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        func(t *testing.T) error
+		outOfRange bool // whether the error wraps ErrFloorOutOfRange
+	}{
+		{
+			name: "Starting below floor 0",
+			err: func(t *testing.T) error {
+				_, err := car.NewShaft(-1, 9, 1)
+				return err
+			},
+			outOfRange: true,
+		},
+		{
+			name: "Too short for the separation",
+			err: func(t *testing.T) error {
+				_, err := car.NewShaft(3, 4, 2)
+				return err
+			},
+			outOfRange: true,
+		},
+		{
+			name: "Taller than the floors served",
+			err: func(t *testing.T) error {
+				_, err := car.NewCar(8, car.WithShaft(newShaft(t, 0, 9, 1), car.LowerTwin))
+				return err
+			},
+			outOfRange: true,
+		},
+		{
+			name: "Starting too close to the twin",
+			err: func(t *testing.T) error {
+				s := newShaft(t, 0, 9, 2)
+				newCar(t, 10, car.WithShaft(s, car.LowerTwin), car.WithFloor(4))
+				_, err := car.NewCar(10, car.WithShaft(s, car.UpperTwin), car.WithFloor(5))
+				return err
+			},
+			outOfRange: true,
+		},
+		{
+			name: "Two lower cars",
+			err: func(t *testing.T) error {
+				s := newShaft(t, 0, 9, 2)
+				newCar(t, 10, car.WithShaft(s, car.LowerTwin))
+				_, err := car.NewCar(10, car.WithShaft(s, car.LowerTwin), car.WithFloor(3))
+				return err
+			},
+		},
+		{
+			name: "Two upper cars",
+			err: func(t *testing.T) error {
+				s := newShaft(t, 0, 9, 2)
+				newCar(t, 10, car.WithShaft(s, car.UpperTwin), car.WithFloor(9))
+				_, err := car.NewCar(10, car.WithShaft(s, car.UpperTwin), car.WithFloor(7))
+				return err
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.err(t)
+			require.Error(t, err)
+			if tc.outOfRange {
+				assert.ErrorIs(t, err, car.ErrFloorOutOfRange)
+			}
+		})
+	}
+
+	s := newShaft(t, 0, 9, 2)
+	newCar(t, 10, car.WithShaft(s, car.LowerTwin), car.WithFloor(4))
+	newCar(t, 10, car.WithShaft(s, car.UpperTwin), car.WithFloor(6))
+}
+
+func TestTwinsKeepTheirDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lowerFloor, upperFloor int
+		lowerCalls, upperCalls []int
+	}{
+		{
+			name:       "Idle upper car moves out of the way",
+			lowerFloor: 0, upperFloor: 4,
+			lowerCalls: []int{7},
+		},
+		{
+			name:       "Idle lower car moves out of the way",
+			lowerFloor: 5, upperFloor: 9,
+			upperCalls: []int{2},
+		},
+		{
+			name:       "Cars wanting to pass each other both get there",
+			lowerFloor: 2, upperFloor: 6,
+			lowerCalls: []int{7}, upperCalls: []int{3},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newShaft(t, 0, 9, 2)
+			lower := newCar(t, 10, car.WithShaft(s, car.LowerTwin), car.WithFloor(tc.lowerFloor), car.WithCalls(tc.lowerCalls))
+			upper := newCar(t, 10, car.WithShaft(s, car.UpperTwin), car.WithFloor(tc.upperFloor), car.WithCalls(tc.upperCalls))
+
+			lowerStops, upperStops := []int{}, []int{}
+			for range 40 {
+				lower.Tick()
+				upper.Tick()
+				assert.GreaterOrEqual(t, upper.Floor()-lower.Floor(), 2)
+				if lower.Status() == car.Loading {
+					lowerStops = append(lowerStops, lower.Floor())
+				}
+				if upper.Status() == car.Loading {
+					upperStops = append(upperStops, upper.Floor())
+				}
+			}
+			assert.Equal(t, tc.lowerCalls, nilIfEmpty(lowerStops))
+			assert.Equal(t, tc.upperCalls, nilIfEmpty(upperStops))
+		})
+	}
+}
+
+func nilIfEmpty(floors []int) []int {
+	if len(floors) == 0 {
+		return nil
+	}
+	return floors
+}
+
+func TestTwinsRecallAsNearAsTheyCan(t *testing.T) {
+	s := newShaft(t, 0, 9, 1)
+	lower := newCar(t, 10, car.WithShaft(s, car.LowerTwin), car.WithFloor(4))
+	upper := newCar(t, 10, car.WithShaft(s, car.UpperTwin), car.WithFloor(5))
+
+	lower.Recall(0)
+	upper.Recall(0)
+	for range 10 {
+		lower.Tick()
+		upper.Tick()
+	}
+	assert.Equal(t, 0, lower.Floor())
+	assert.Equal(t, 1, upper.Floor())
+	assert.Equal(t, car.Open, upper.Door())
+}
+
+// schedule drives a Car of numFloors through a sequence of hall calls, presses and steps, one per byte of ops,
+// then lets it run until it has answered them all. Along the way it checks that the Car never passes a floor it
+// was called to, and that its Score is never negative.
+func schedule(t *testing.T, numFloors int, ops []byte) {
+	t.Helper()
+	c := newCar(t, numFloors)
+	tick := func() {
+		floor, calls := c.Floor(), c.Calls()
+		c.Tick()
+		if moved := c.Floor(); moved != floor && slices.Contains(calls, moved) {
+			require.Equal(t, car.Loading, c.Status(), "passed floor %d with calls %v", moved, calls)
+		}
+		for floor := range numFloors {
+			for _, direction := range []car.Direction{car.Up, car.Down} {
+				require.GreaterOrEqual(t, c.Score(floor, direction), time.Duration(0))
+			}
+		}
+	}
+
+	for _, op := range ops {
+		floor := int(op>>2) % numFloors
+		switch op & 3 {
+		case 0:
+			c.Call(floor)
+		case 1:
+			c.Press(floor)
+		default:
+			tick()
+		}
+	}
+
+	// a sweep up and a sweep down, stopping at every floor on the way, answer every call
+	for range 4 * numFloors {
+		tick()
+	}
+	assert.Empty(t, c.Calls())
+	assert.Equal(t, car.Parked, c.Status())
+}
+
+func FuzzCarSchedule(f *testing.F) {
+	f.Add(uint8(5), []byte{0x0c, 0x02, 0x05, 0x02, 0x02, 0x00})
+	f.Add(uint8(10), []byte{0x24, 0x02, 0x02, 0x02, 0x01, 0x08, 0x1d, 0x02})
+	f.Add(uint8(2), []byte{0x04, 0x03, 0x00, 0x03})
+
+	f.Fuzz(func(t *testing.T, numFloors uint8, ops []byte) {
+		schedule(t, 2+int(numFloors)%31, ops)
+	})
+}
+
+func TestScheduleOverRandomCalls(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 200 {
+		ops := make([]byte, r.IntN(64))
+		for i := range ops {
+			ops[i] = byte(r.IntN(256))
+		}
+		schedule(t, 2+r.IntN(20), ops)
+	}
+}
+
+func TestFloorsOutOfRange(t *testing.T) {
+	for _, numFloors := range []int{0, -1} {
+		_, err := car.NewCar(numFloors)
+		assert.ErrorIs(t, err, car.ErrFloorOutOfRange, "%d floors", numFloors)
+	}
+	_, err := car.NewCar(5, car.WithFloor(5))
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange)
+	_, err = car.NewCar(5, car.WithCalls([]int{-1, 2, 5}))
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange)
+	_, err = car.NewDoubleDeck(10, 10)
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange, "the lobby is not in the building")
+
+	c := newCar(t, 5, car.WithCalls([]int{2}))
+	assert.Equal(t, 5, c.NumFloors())
+
+	_, err = c.Call(7)
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange)
+	_, err = c.Press(-2)
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange)
+	c.CancelCall(5)
+	c.CancelPress(-1)
+	assert.Equal(t, []int{2}, c.Calls())
+
+	d := newDoubleDeck(t, 10, 0)
+	_, err = d.Call(10)
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange, "the upper deck would stop at the top floor, but no deck serves 10")
+	_, err = d.Decks()[1].Press(-1)
+	assert.ErrorIs(t, err, car.ErrFloorOutOfRange)
+	assert.Empty(t, d.Calls())
+}
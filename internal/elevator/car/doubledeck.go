@@ -0,0 +1,311 @@
+package car
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DoubleDeck is a car with two decks one floor apart, which stop together: when the lower deck is at a floor,
+// the upper deck is at the floor above. The lower deck serves the floors of the same parity as the lobby and
+// the upper deck the others, so that every floor is served by one deck and the car always stops with both
+// decks level with a floor. Passengers board the deck at their floor and press their destination; one going
+// to a floor the other deck serves makes the car stop out of step, which is why a building with double-deck
+// cars has a lobby on each deck's level.
+//
+// A DoubleDeck moves as a frame over the stops between the floors, one for each position of the pair of decks,
+// and so answers calls at two floors with every stop it makes. It is safe for concurrent use.
+type DoubleDeck struct {
+	frame     *Car // over stops counting up from 0, with the lower deck below the lowest floor
+	numFloors int
+	lobby     int
+	decks     [2]*Deck
+
+	mu       sync.Mutex // guards loads
+	loads    [2]int
+	capacity int // of each deck
+}
+
+// Deck is one deck of a DoubleDeck, which passengers board and ride. It answers for its own floor, load and
+// buttons, and for everything else the car it is part of.
+type Deck struct {
+	*DoubleDeck
+	offset int // 0 for the lower deck, 1 for the upper
+}
+
+// NewDoubleDeck creates a DoubleDeck serving the given number of floors, with its lower deck serving the lobby.
+// The options configure it as they would a Car: WithFloor stops it with the deck serving the floor at it,
+// WithCalls presses floors on that deck, and WithCapacity sets the capacity of each deck. It returns an error
+// wrapping ErrFloorOutOfRange if the lobby, or a floor given in the options, is not one of the floors.
+func NewDoubleDeck(numFloors, lobby int, options ...Option) (*DoubleDeck, error) {
+	c, err := NewCar(numFloors, options...)
+	if err != nil {
+		return nil, err
+	}
+	d := DoubleDeck{numFloors: numFloors, lobby: lobby, capacity: c.capacity}
+	if lobby < 0 || lobby >= numFloors {
+		return nil, fmt.Errorf("%w: the lobby %d is not one of the %d floors served", ErrFloorOutOfRange, lobby, numFloors)
+	}
+
+	d.frame, err = NewCar(numFloors+1,
+		WithFloor(d.stop(c.floor)),
+		WithDirection(c.direction),
+		WithStatus(c.status),
+		WithCapacity(2*c.capacity),
+		WithProfile(c.profile),
+		WithEnergyModel(c.energy),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, floor := range c.calls() {
+		d.frame.pressed[d.stop(floor)] = true
+		d.frame.buttons[d.stop(floor)] = true
+	}
+
+	d.decks = [2]*Deck{{&d, 0}, {&d, 1}}
+	return &d, nil
+}
+
+// deckFor returns the offset of the deck that serves the floor.
+func (d *DoubleDeck) deckFor(floor int) int {
+	if (floor-d.lobby)%2 == 0 {
+		return 0
+	}
+	return 1
+}
+
+// stop returns the stop of the frame that brings the deck serving the floor to it.
+func (d *DoubleDeck) stop(floor int) int {
+	return floor + 1 - d.deckFor(floor)
+}
+
+// Decks returns the lower and upper decks.
+func (d *DoubleDeck) Decks() []*Deck {
+	return d.decks[:]
+}
+
+// DeckFor returns the deck that serves the floor.
+func (d *DoubleDeck) DeckFor(floor int) *Deck {
+	return d.decks[d.deckFor(floor)]
+}
+
+// Floor returns the floor of the lower deck, or of the upper deck when the lower is below the lowest floor.
+func (d *DoubleDeck) Floor() int {
+	return max(d.frame.Floor()-1, 0)
+}
+
+func (d *DoubleDeck) Direction() Direction { return d.frame.Direction() }
+func (d *DoubleDeck) Status() Status       { return d.frame.Status() }
+func (d *DoubleDeck) Door() Door           { return d.frame.Door() }
+func (d *DoubleDeck) Mode() Mode           { return d.frame.Mode() }
+func (d *DoubleDeck) Fault() Fault         { return d.frame.Fault() }
+func (d *DoubleDeck) Energy() Meter        { return d.frame.Energy() }
+
+// Load returns the number of passengers aboard both decks.
+func (d *DoubleDeck) Load() int { return d.frame.Load() }
+
+// NumFloors returns the number of floors the car serves between its decks.
+func (d *DoubleDeck) NumFloors() int { return d.numFloors }
+
+// Capacity returns the most passengers both decks can carry.
+func (d *DoubleDeck) Capacity() int { return d.frame.Capacity() }
+
+// Calls returns the floors either deck will stop at, in order.
+func (d *DoubleDeck) Calls() []int {
+	calls := []int{}
+	for _, stop := range d.frame.Calls() {
+		for _, floor := range []int{stop - 1, stop} {
+			if floor >= 0 && floor < d.numFloors {
+				calls = append(calls, floor)
+			}
+		}
+	}
+	return calls
+}
+
+// Score estimates how long the car will take to bring the deck serving the floor to it; a call at a floor
+// where the other deck is already stopping costs no extra stop.
+func (d *DoubleDeck) Score(floor int, direction Direction) time.Duration {
+	return d.frame.Score(d.stop(floor), direction)
+}
+
+// EstimateEnergy estimates the energy the car would use to bring the deck serving the floor to it.
+func (d *DoubleDeck) EstimateEnergy(floor int, direction Direction) float64 {
+	return d.frame.EstimateEnergy(d.stop(floor), direction)
+}
+
+// checkFloor returns an error wrapping ErrFloorOutOfRange if the floor is not one of the floors the car serves.
+func (d *DoubleDeck) checkFloor(floor int) error {
+	if floor < 0 || floor >= d.numFloors {
+		return fmt.Errorf("%w: %d is not one of the %d floors served", ErrFloorOutOfRange, floor, d.numFloors)
+	}
+	return nil
+}
+
+// Reaches reports whether the deck serving the floor can stop at it.
+func (d *DoubleDeck) Reaches(floor int) bool {
+	return floor >= 0 && floor < d.numFloors && d.frame.Reaches(d.stop(floor))
+}
+
+// Call sends the deck serving the floor to it to answer a hall call, returning the floors either deck will stop at.
+func (d *DoubleDeck) Call(floor int) ([]bool, error) {
+	if err := d.checkFloor(floor); err != nil {
+		return nil, err
+	}
+	return d.buttons(d.frame.Call(d.stop(floor)))
+}
+
+func (d *DoubleDeck) CancelCall(floor int) { d.frame.CancelCall(d.stop(floor)) }
+
+// Press presses the button for the floor on the deck that serves it, returning the floors either deck will stop at.
+func (d *DoubleDeck) Press(floor int) ([]bool, error) {
+	if err := d.checkFloor(floor); err != nil {
+		return nil, err
+	}
+	return d.buttons(d.frame.Press(d.stop(floor)))
+}
+
+// buttons turns the frame's buttons, by stop, into the floors either deck will stop at.
+func (d *DoubleDeck) buttons(stops []bool, err error) ([]bool, error) {
+	if err != nil {
+		return nil, err
+	}
+	floors := make([]bool, d.numFloors)
+	for floor := range floors {
+		floors[floor] = stops[floor] || stops[floor+1]
+	}
+	return floors, nil
+}
+
+func (d *DoubleDeck) CancelPress(floor int) { d.frame.CancelPress(d.stop(floor)) }
+
+// Recall sends the car non-stop to bring the deck serving the floor to it; see [Car.Recall].
+func (d *DoubleDeck) Recall(floor int) { d.frame.Recall(d.stop(floor)) }
+
+func (d *DoubleDeck) Reopen() bool                 { return d.frame.Reopen() }
+func (d *DoubleDeck) Tick()                        { d.frame.Tick() }
+func (d *DoubleDeck) SetMode(mode Mode)            { d.frame.SetMode(mode) }
+func (d *DoubleDeck) FireService()                 { d.frame.FireService() }
+func (d *DoubleDeck) Restore()                     { d.frame.Restore() }
+func (d *DoubleDeck) Fail(fault Fault)             { d.frame.Fail(fault) }
+func (d *DoubleDeck) Repair()                      { d.frame.Repair() }
+func (d *DoubleDeck) AddStandby(dur time.Duration) { d.frame.AddStandby(dur) }
+
+// Enter boards a passenger on the lower deck, or the upper deck if the lower is full, returning false if both are.
+func (d *DoubleDeck) Enter() bool {
+	return d.decks[0].Enter() || d.decks[1].Enter()
+}
+
+// Exit lets a passenger off the upper deck, or the lower deck if the upper is empty.
+func (d *DoubleDeck) Exit() {
+	if d.decks[1].Load() > 0 {
+		d.decks[1].Exit()
+		return
+	}
+	d.decks[0].Exit()
+}
+
+// Floor returns the floor the deck is at, which is outside the building when the other deck is at the
+// lowest or highest floor.
+func (d *Deck) Floor() int {
+	return d.frame.Floor() - 1 + d.offset
+}
+
+// Load returns the number of passengers aboard the deck.
+func (d *Deck) Load() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.loads[d.offset]
+}
+
+// Capacity returns the most passengers the deck can carry.
+func (d *Deck) Capacity() int {
+	return d.capacity
+}
+
+// Enter boards a passenger, returning false if the deck is full.
+func (d *Deck) Enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.loads[d.offset] >= d.capacity || !d.frame.Enter() {
+		return false
+	}
+	d.loads[d.offset]++
+	return true
+}
+
+// Exit lets a passenger off the deck.
+func (d *Deck) Exit() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.loads[d.offset] > 0 {
+		d.loads[d.offset]--
+		d.frame.Exit()
+	}
+}
+
+// NumFloors returns the number of floors the car serves between its decks.
+func (d *Deck) NumFloors() int { return d.numFloors }
+
+// Calls returns the floors the deck will stop at, in order.
+func (d *Deck) Calls() []int {
+	calls := []int{}
+	for _, stop := range d.frame.Calls() {
+		if floor := stop - 1 + d.offset; floor >= 0 && floor < d.numFloors {
+			calls = append(calls, floor)
+		}
+	}
+	return calls
+}
+
+// stop returns the stop of the frame that brings the deck to the floor.
+func (d *Deck) stop(floor int) int {
+	return floor + 1 - d.offset
+}
+
+// Press presses the deck's button for the floor, returning the floors the deck will stop at. The car stops out
+// of step if the floor is served by the other deck.
+func (d *Deck) Press(floor int) ([]bool, error) {
+	if err := d.checkFloor(floor); err != nil {
+		return nil, err
+	}
+	return d.buttons(d.frame.Press(d.stop(floor)))
+}
+
+func (d *Deck) CancelPress(floor int) { d.frame.CancelPress(d.stop(floor)) }
+
+// Call sends the deck to the floor, returning the floors the deck will stop at.
+func (d *Deck) Call(floor int) ([]bool, error) {
+	if err := d.checkFloor(floor); err != nil {
+		return nil, err
+	}
+	return d.buttons(d.frame.Call(d.stop(floor)))
+}
+
+// buttons turns the frame's buttons, by stop, into the floors the deck will stop at.
+func (d *Deck) buttons(stops []bool, err error) ([]bool, error) {
+	if err != nil {
+		return nil, err
+	}
+	floors := make([]bool, d.numFloors)
+	for floor := range floors {
+		floors[floor] = stops[d.stop(floor)]
+	}
+	return floors, nil
+}
+
+func (d *Deck) CancelCall(floor int) { d.frame.CancelCall(d.stop(floor)) }
+
+// Reaches reports whether the deck can stop at the floor.
+func (d *Deck) Reaches(floor int) bool {
+	return floor >= 0 && floor < d.numFloors && d.frame.Reaches(d.stop(floor))
+}
+
+func (d *Deck) Score(floor int, direction Direction) time.Duration {
+	return d.frame.Score(d.stop(floor), direction)
+}
+
+func (d *Deck) EstimateEnergy(floor int, direction Direction) float64 {
+	return d.frame.EstimateEnergy(d.stop(floor), direction)
+}
@@ -0,0 +1,170 @@
+package car
+
+import "time"
+
+const gravity = 9.81 // m/s²
+
+// joulesPerKWh converts the joules of the physics into the kilowatt hours on the electricity bill.
+const joulesPerKWh = 3.6e6
+
+// EnergyModel describes the electrical and mechanical characteristics that decide how much energy the Car uses.
+type EnergyModel struct {
+	CarMass         float64 // kg, the empty car
+	RatedLoad       float64 // kg
+	Balance         float64 // the fraction of the rated load the counterweight balances, typically 0.4 to 0.5
+	PassengerMass   float64 // kg per passenger
+	Friction        float64 // newtons of guide rail and rope friction opposing the car whichever way it moves
+	Efficiency      float64 // of the motor and drive, from 0 to 1
+	Regenerative    bool    // whether the drive returns braking energy to the building
+	RegenEfficiency float64 // the fraction of braking energy a regenerative drive recovers, from 0 to 1
+	StandbyPower    float64 // watts drawn by the controller, lights and fan while the car is idle
+	DoorPower       float64 // watts drawn by the door operator while the doors are moving
+}
+
+// DefaultEnergyModel is a geared traction elevator rated for 1600 kg without a regenerative drive.
+var DefaultEnergyModel = EnergyModel{
+	CarMass:         1500,
+	RatedLoad:       1600,
+	Balance:         0.45,
+	PassengerMass:   75,
+	Friction:        500,
+	Efficiency:      0.7,
+	RegenEfficiency: 0.6,
+	StandbyPower:    200,
+	DoorPower:       150,
+}
+
+// WithEnergyModel is a functional option that sets the EnergyModel of the Car.
+func WithEnergyModel(m EnergyModel) Option {
+	return func(c *Car) {
+		c.energy = m
+	}
+}
+
+// Meter is a reading of the energy a Car has used, in kilowatt hours.
+type Meter struct {
+	Motion      float64 // drawn by the motor to move the car
+	Regenerated float64 // returned to the building by a regenerative drive
+	Doors       float64 // drawn by the door operator
+	Standby     float64 // drawn while the car sits idle
+	Trips       int     // runs from one stop to the next
+	Passengers  int     // passengers who boarded
+}
+
+// Total returns the net energy used by the Car.
+func (m Meter) Total() float64 {
+	return m.Motion + m.Doors + m.Standby - m.Regenerated
+}
+
+// Energy returns a reading of the energy the Car has used so far.
+func (c *Car) Energy() Meter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.meter
+}
+
+// AddStandby meters the energy the Car draws while sitting idle for the given duration.
+func (c *Car) AddStandby(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.meter.Standby += c.energy.StandbyPower * d.Seconds() / joulesPerKWh
+}
+
+// meterTick meters the energy used by the Tick that just moved the Car from the given floor and door state.
+func (c *Car) meterTick(floor int, door Door) {
+	if door == Closed && c.door == Open {
+		c.meterDoors()
+	}
+
+	moved := c.floor - floor
+	if moved != 0 {
+		c.run++
+		// lifting the imbalance between the loaded car and the counterweight, and overcoming friction
+		lift := c.imbalance() * gravity * float64(moved) * c.profile.FloorHeight
+		c.meterWork(lift + c.energy.Friction*c.profile.FloorHeight)
+	}
+
+	if c.run > 0 && (moved == 0 || c.status != Traveling) {
+		// the run is over: the moving masses were brought up to speed and back to rest
+		speed := c.profile.PeakSpeed(c.run)
+		kinetic := 0.5 * c.movingMass() * speed * speed
+		c.meterWork(kinetic)
+		c.meterWork(-kinetic)
+
+		c.meter.Trips++
+		c.run = 0
+	}
+}
+
+// meterDoors meters one door cycle, opening now and closing later.
+func (c *Car) meterDoors() {
+	c.meter.Doors += c.energy.DoorPower * c.profile.DoorTime.Seconds() / joulesPerKWh
+}
+
+// meterWork meters mechanical work done by the motor, in joules.
+func (c *Car) meterWork(joules float64) {
+	drawn, regenerated := c.energy.electrical(joules)
+	c.meter.Motion += drawn / joulesPerKWh
+	c.meter.Regenerated += regenerated / joulesPerKWh
+}
+
+// electrical converts mechanical work done by the motor into the electrical energy drawn from or
+// returned to the building, all in joules. Negative work is braking, which a regenerative drive
+// partly recovers and any other drive burns off as heat.
+func (m EnergyModel) electrical(work float64) (drawn, regenerated float64) {
+	if work >= 0 {
+		return work / m.Efficiency, 0
+	}
+	if m.Regenerative {
+		return 0, -work * m.RegenEfficiency
+	}
+	return 0, 0
+}
+
+// EstimateEnergy estimates the net energy, in watt hours, the Car would use to answer a hall call at the given floor:
+// the run there with its current load, and the stop to open its doors. The estimate is negative when a
+// regenerative drive would recover more than the car draws, such as a full car heading down.
+func (c *Car) EstimateEnergy(floor int, direction Direction) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	floors := floor - c.floor
+	distance := float64(abs(floors)) * c.profile.FloorHeight
+
+	joules := c.energy.DoorPower * c.profile.DoorTime.Seconds()
+	if floors != 0 {
+		speed := c.profile.PeakSpeed(abs(floors))
+		kinetic := 0.5 * c.movingMass() * speed * speed
+		lift := c.imbalance()*gravity*float64(floors)*c.profile.FloorHeight + c.energy.Friction*distance
+
+		for _, work := range []float64{kinetic, lift, -kinetic} {
+			drawn, regenerated := c.energy.electrical(work)
+			joules += drawn - regenerated
+		}
+	}
+
+	return joules / 3600
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// imbalance returns how much heavier the loaded car is than its counterweight, in kg.
+// It is negative when the counterweight is heavier, so that going up is downhill.
+func (c *Car) imbalance() float64 {
+	counterweight := c.energy.CarMass + c.energy.Balance*c.energy.RatedLoad
+	return c.energy.CarMass + c.loadMass() - counterweight
+}
+
+// movingMass returns the mass of everything set in motion with the car, in kg.
+func (c *Car) movingMass() float64 {
+	counterweight := c.energy.CarMass + c.energy.Balance*c.energy.RatedLoad
+	return c.energy.CarMass + c.loadMass() + counterweight
+}
+
+func (c *Car) loadMass() float64 {
+	return float64(c.load) * c.energy.PassengerMass
+}
@@ -0,0 +1,111 @@
+package car_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/elevator/car"
+)
+
+// ride boards the given number of passengers and runs the car from one floor to another.
+func ride(t *testing.T, model car.EnergyModel, passengers, from, to int) car.Meter {
+	t.Helper()
+	c := newCar(t, 10, car.WithFloor(from), car.WithEnergyModel(model))
+	for range passengers {
+		c.Enter()
+	}
+	_, err := c.Call(to)
+	require.NoError(t, err)
+	for c.Floor() != to || c.Status() != car.Loading {
+		c.Tick()
+	}
+	return c.Energy()
+}
+
+func TestEnergyDependsOnLoadAndDirection(t *testing.T) {
+	model := car.DefaultEnergyModel
+
+	emptyUp := ride(t, model, 0, 0, 5)
+	fullUp := ride(t, model, 16, 0, 5)
+	emptyDown := ride(t, model, 0, 5, 0)
+	fullDown := ride(t, model, 16, 5, 0)
+
+	assert.Greater(t, fullUp.Motion, emptyUp.Motion, "lifting a full car is uphill")
+	assert.Greater(t, emptyDown.Motion, fullDown.Motion, "lowering an empty car is uphill for the counterweight")
+	assert.Zero(t, fullDown.Regenerated, "without a regenerative drive braking energy is lost")
+	assert.Equal(t, 1, fullUp.Trips)
+	assert.Equal(t, 16, fullUp.Passengers)
+	assert.Greater(t, fullUp.Doors, 0.0)
+}
+
+func TestEnergyIsRegenerated(t *testing.T) {
+	model := car.DefaultEnergyModel
+	model.Regenerative = true
+
+	fullDown := ride(t, model, 16, 5, 0)
+	emptyUp := ride(t, model, 0, 0, 5)
+	assert.Greater(t, fullDown.Regenerated, 0.0)
+	assert.Greater(t, emptyUp.Regenerated, 0.0)
+
+	plain := ride(t, car.DefaultEnergyModel, 16, 5, 0)
+	assert.Less(t, fullDown.Total(), plain.Total())
+}
+
+func TestEnergyGrowsWithDistance(t *testing.T) {
+	model := car.DefaultEnergyModel
+	assert.Greater(t, ride(t, model, 12, 0, 8).Motion, ride(t, model, 12, 0, 2).Motion)
+}
+
+func TestStandbyEnergy(t *testing.T) {
+	c := newCar(t, 5)
+	c.AddStandby(time.Hour)
+	assert.InDelta(t, car.DefaultEnergyModel.StandbyPower/1000, c.Energy().Standby, 1e-9)
+	assert.InDelta(t, c.Energy().Standby, c.Energy().Total(), 1e-9)
+}
+
+func TestCapacity(t *testing.T) {
+	c := newCar(t, 5, car.WithCapacity(2))
+	assert.True(t, c.Enter())
+	assert.True(t, c.Enter())
+	assert.False(t, c.Enter())
+	assert.Equal(t, 2, c.Load())
+
+	c.Exit()
+	assert.Equal(t, 1, c.Load())
+	assert.Equal(t, 2, c.Energy().Passengers)
+}
+
+func TestPeakSpeed(t *testing.T) {
+	p := car.DefaultProfile
+	assert.Less(t, p.PeakSpeed(1), p.Speed, "a one floor run never reaches rated speed")
+	assert.Equal(t, p.Speed, p.PeakSpeed(20))
+}
+
+func TestEstimateEnergy(t *testing.T) {
+	regen := car.DefaultEnergyModel
+	regen.Regenerative = true
+
+	loaded := func(model car.EnergyModel, passengers, floor int) *car.Car {
+		c := newCar(t, 10, car.WithFloor(floor), car.WithEnergyModel(model))
+		for range passengers {
+			c.Enter()
+		}
+		return c
+	}
+
+	assert.Less(t, loaded(regen, 16, 8).EstimateEnergy(0, car.Up), 0.0, "a full car running down gives energy back")
+	assert.Less(t, loaded(regen, 0, 0).EstimateEnergy(8, car.Down), 0.0, "so does an empty car running up")
+	assert.Greater(t, loaded(regen, 16, 0).EstimateEnergy(8, car.Down), 0.0, "a full car running up draws energy")
+	assert.Greater(t,
+		loaded(car.DefaultEnergyModel, 16, 8).EstimateEnergy(0, car.Up),
+		loaded(regen, 16, 8).EstimateEnergy(0, car.Up),
+		"without regeneration braking energy is lost",
+	)
+
+	doorsOnly := loaded(regen, 0, 3).EstimateEnergy(3, car.Up)
+	assert.Greater(t, doorsOnly, 0.0)
+	assert.Greater(t, loaded(regen, 12, 3).EstimateEnergy(6, car.Up), doorsOnly)
+}
@@ -0,0 +1,56 @@
+package car
+
+import "fmt"
+
+// Fault is an enum type that represents a breakdown of the Car.
+type Fault int
+
+const (
+	NoFault        Fault = iota
+	DoorFault            // the doors will not open, so the car stands at a floor with its passengers inside.
+	Stall                // the car stopped between floors.
+	LevellingError       // the car stopped out of level with the landing, so it keeps its doors closed.
+)
+
+var faultNames = map[Fault]string{
+	NoFault:        "no fault",
+	DoorFault:      "door fault",
+	Stall:          "stall",
+	LevellingError: "levelling error",
+}
+
+func (f Fault) String() string {
+	if name, ok := faultNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("Fault(%d)", int(f))
+}
+
+// Fault returns the breakdown the Car is suffering, or NoFault if it is working.
+func (c *Car) Fault() Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fault
+}
+
+// Fail breaks the Car down where it is. It stops with its doors closed, trapping anyone aboard,
+// and will not move again until it is repaired. Its calls are kept for when it is.
+func (c *Car) Fail(fault Fault) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fault = fault
+	c.door = Closed
+	c.held = false
+	if fault == Stall {
+		c.status = Traveling
+	} else {
+		c.status = Parked
+	}
+}
+
+// Repair fixes the Car's fault so that it carries on with its calls.
+func (c *Car) Repair() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fault = NoFault
+}
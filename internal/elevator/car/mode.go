@@ -0,0 +1,162 @@
+package car
+
+import "fmt"
+
+// Mode is an enum type that represents the operating mode of the Car.
+type Mode int
+
+const (
+	Normal       Mode = iota // answers car and hall calls as usual.
+	FireRecall               // Phase I: returning non-stop to the recall floor, where it parks with its doors open.
+	FireService              // Phase II: under the manual control of a firefighter.
+	OutOfService             // finishes the calls it already has, then parks and accepts no more.
+	Inspection               // stopped where it is under the control of a technician; calls are cancelled.
+	Independent              // answers car calls only, for moving furniture or a VIP.
+	Attendant                // answers car and hall calls, with an attendant holding the doors at each stop.
+)
+
+var modeNames = map[Mode]string{
+	Normal:       "normal",
+	FireRecall:   "fire recall",
+	FireService:  "fire service",
+	OutOfService: "out of service",
+	Inspection:   "inspection",
+	Independent:  "independent service",
+	Attendant:    "attendant",
+}
+
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Dispatchable reports whether a Car in this mode should be assigned hall calls.
+func (m Mode) Dispatchable() bool {
+	return m == Normal || m == Attendant
+}
+
+func (m Mode) acceptsCalls() bool {
+	return m != FireRecall && m != OutOfService && m != Inspection
+}
+
+// Mode returns the operating mode of the Car.
+func (c *Car) Mode() Mode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mode
+}
+
+// SetMode switches the Car between the operating modes Normal, OutOfService, Inspection, Independent and Attendant.
+// Fire modes are entered with [Car.Recall] and [Car.FireService] and left with [Car.Restore], so they are ignored here.
+// A Car in a fire mode switches to the new mode once it is restored.
+func (c *Car) SetMode(mode Mode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if mode == FireRecall || mode == FireService {
+		return
+	}
+	if c.fireMode() {
+		c.resume = mode
+		return
+	}
+
+	c.mode = mode
+	if mode == Inspection {
+		c.clearCalls()
+	}
+}
+
+// Recall starts Phase I emergency recall. All calls are cancelled, and the Car closes its doors
+// and travels non-stop to the given floor, where it parks with its doors open. A Car sharing a Shaft
+// stops as near to the floor as its twin lets it. The Car remembers the mode it was in, to return to
+// it on [Car.Restore].
+func (c *Car) Recall(floor int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.shaft != nil {
+		low, high := c.shaft.span(c.twin)
+		floor = min(max(floor, low), high)
+	}
+	if !c.fireMode() {
+		c.resume = c.mode
+	}
+	c.mode = FireRecall
+	c.recall = floor
+	c.clearCalls()
+
+	if c.floor != floor {
+		c.door = Closed
+		c.status = Traveling
+		c.updateDirection(floor)
+	}
+}
+
+// FireService starts Phase II operation. The Car answers only its own calls, arriving with its doors
+// closed, and holds still while the firefighter has the doors open. See [Car.OpenDoors].
+func (c *Car) FireService() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mode = FireService
+	if c.status == Loading {
+		c.status = Parked
+	}
+}
+
+// OpenDoors opens the doors of a Car in FireService mode that is stopped at a floor.
+func (c *Car) OpenDoors() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mode == FireService && c.status == Parked {
+		c.door = Open
+	}
+}
+
+// CloseDoors closes the doors of a Car in FireService mode.
+func (c *Car) CloseDoors() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mode == FireService {
+		c.door = Closed
+	}
+}
+
+// Restore returns a Car in a fire mode to the mode it was in before the recall, parked with its doors closed
+// and no calls. A Car that had not yet reached the recall floor parks where it is.
+func (c *Car) Restore() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.fireMode() {
+		return
+	}
+	c.mode = c.resume
+	c.clearCalls()
+	c.status = Parked
+	c.door = Closed
+}
+
+func (c *Car) fireMode() bool {
+	return c.mode == FireRecall || c.mode == FireService
+}
+
+func (c *Car) tickRecall() {
+	if c.floor == c.recall {
+		c.status = Parked
+		c.door = Open
+		return
+	}
+
+	c.updateDirection(c.recall)
+	if !c.updateFloor(c.recall) {
+		// the twin is in the way, so stop here instead
+		c.recall = c.floor
+	}
+	c.status = Traveling
+}
+
+func (c *Car) clearCalls() {
+	for floor := range c.buttons {
+		c.clearCall(floor)
+	}
+}
@@ -0,0 +1,67 @@
+package car
+
+import (
+	"math"
+	"time"
+)
+
+// Profile describes how the Car moves between floors and works its doors.
+type Profile struct {
+	FloorHeight  float64       // metres from one floor to the next
+	Speed        float64       // rated speed in metres per second
+	Acceleration float64       // metres per second squared, used for both speeding up and slowing down
+	DoorTime     time.Duration // time to open and close the doors once
+	Dwell        time.Duration // time the doors stay open for passengers at each stop
+}
+
+// DefaultProfile is a mid-rise traction elevator.
+var DefaultProfile = Profile{
+	FloorHeight:  3.5,
+	Speed:        2.5,
+	Acceleration: 1.0,
+	DoorTime:     5 * time.Second,
+	Dwell:        3 * time.Second,
+}
+
+// WithProfile is a functional option that sets the motion Profile of the Car.
+func WithProfile(p Profile) Option {
+	return func(c *Car) {
+		c.profile = p
+	}
+}
+
+// Profile returns the motion Profile of the Car.
+func (c *Car) Profile() Profile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.profile
+}
+
+// PeakSpeed returns the fastest the Car gets on a run of the given number of floors.
+// Short runs never reach the rated speed, since the car has to start slowing down halfway.
+func (p Profile) PeakSpeed(floors int) float64 {
+	distance := float64(floors) * p.FloorHeight
+	return math.Min(p.Speed, math.Sqrt(p.Acceleration*distance))
+}
+
+// RunTime returns how long the Car takes to travel the given number of floors without stopping,
+// from rest to rest.
+func (p Profile) RunTime(floors int) time.Duration {
+	if floors == 0 {
+		return 0
+	}
+
+	distance := float64(floors) * p.FloorHeight
+	speed := p.PeakSpeed(floors)
+
+	// speeding up and slowing down each take speed/acceleration, covering speed²/acceleration between them,
+	// and the rest of the distance is covered at speed
+	seconds := 2*speed/p.Acceleration + (distance-speed*speed/p.Acceleration)/speed
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// StopTime returns how long the Car spends at a stop, opening its doors, letting passengers on and off,
+// and closing them again.
+func (p Profile) StopTime() time.Duration {
+	return p.DoorTime + p.Dwell
+}
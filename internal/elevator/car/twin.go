@@ -0,0 +1,179 @@
+package car
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Twin says which of the two cars sharing a Shaft a Car is.
+type Twin int
+
+const (
+	LowerTwin Twin = iota
+	UpperTwin
+)
+
+func (t Twin) String() string {
+	if t == LowerTwin {
+		return "lower"
+	}
+	return "upper"
+}
+
+// Shaft is a hoistway shared by twin cars, one above the other, which run independently but must never come
+// closer than the Shaft's separation, in floors. So the lower car cannot reach the top floors of the Shaft,
+// nor the upper car the bottom floors; see [Car.Reaches].
+//
+// The Shaft keeps the cars apart as they move: a car whose next floor is too close to its twin holds where it
+// is until the twin moves on. A twin with nothing to do moves out of the way of a car it holds up, and when both
+// hold each other up, each wanting to pass the other, the lower car gives way.
+//
+// The cars must start at least the separation apart. A Shaft is safe for concurrent use.
+type Shaft struct {
+	lowest     int
+	highest    int
+	separation int
+
+	mu      sync.Mutex
+	floors  [2]int
+	blocked [2]bool // whether each car was held up by its twin when it last moved
+	joined  [2]bool // whether each of the twins has been put into the Shaft
+}
+
+// NewShaft creates a Shaft running from the lowest floor to the highest, whose cars keep at least separation
+// floors apart. A separation below 1 is taken as 1, so the cars are never at the same floor.
+// It returns an error wrapping ErrFloorOutOfRange if the lowest floor is below 0, or if the Shaft is too short
+// for its cars to keep their distance.
+func NewShaft(lowest, highest, separation int) (*Shaft, error) {
+	separation = max(separation, 1)
+	if lowest < 0 {
+		return nil, fmt.Errorf("%w: a Shaft cannot start below floor 0, at %d", ErrFloorOutOfRange, lowest)
+	}
+	if highest-lowest < separation {
+		return nil, fmt.Errorf("%w: a Shaft from %d to %d is too short for cars %d floors apart", ErrFloorOutOfRange, lowest, highest, separation)
+	}
+	s := Shaft{lowest: lowest, highest: highest, separation: separation}
+	s.floors = [2]int{lowest, highest}
+	return &s, nil
+}
+
+// WithShaft is a functional option that puts the Car into the Shaft as one of its twins.
+func WithShaft(s *Shaft, twin Twin) Option {
+	return func(c *Car) {
+		c.shaft = s
+		c.twin = twin
+	}
+}
+
+// reaches reports whether the twin can reach the floor without coming too close to the other end of the Shaft.
+func (s *Shaft) reaches(twin Twin, floor int) bool {
+	low, high := s.span(twin)
+	return floor >= low && floor <= high
+}
+
+// span returns the lowest and highest floors the twin can reach.
+func (s *Shaft) span(twin Twin) (low, high int) {
+	if twin == LowerTwin {
+		return s.lowest, s.highest - s.separation
+	}
+	return s.lowest + s.separation, s.highest
+}
+
+// check returns an error if the twin is already in the Shaft, or one wrapping ErrFloorOutOfRange if the Shaft
+// runs past the floors a Car serves or the twin would start too close to the other car.
+func (s *Shaft) check(twin Twin, numFloors, floor int) error {
+	s.mu.Lock()
+	joined := s.joined[twin]
+	s.mu.Unlock()
+	if joined {
+		return fmt.Errorf("car: the Shaft already has its %s car", twin)
+	}
+	if s.highest >= numFloors {
+		return fmt.Errorf("%w: a Shaft up to %d does not fit the %d floors served", ErrFloorOutOfRange, s.highest, numFloors)
+	}
+	if !s.clear(twin, floor) {
+		return fmt.Errorf("%w: %d is within %d floors of the Car's twin", ErrFloorOutOfRange, floor, s.separation)
+	}
+	return nil
+}
+
+// clear reports whether the twin can move to the floor while keeping its distance from the other car.
+func (s *Shaft) clear(twin Twin, floor int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if twin == LowerTwin {
+		return s.floors[UpperTwin]-floor >= s.separation
+	}
+	return floor-s.floors[LowerTwin] >= s.separation
+}
+
+// join puts the twin into the Shaft at the floor.
+func (s *Shaft) join(twin Twin, floor int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.joined[twin] = true
+	s.floors[twin] = floor
+}
+
+// report records where the twin is after a step, and whether it was held up.
+func (s *Shaft) report(twin Twin, floor int, blocked bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.floors[twin] = floor
+	s.blocked[twin] = blocked
+}
+
+// inTheWay reports whether the twin should move away from the other car, which it is holding up: because
+// the twin is idle, or because it is the lower car and held up in turn.
+func (s *Shaft) inTheWay(twin Twin, idle, blocked bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	other := 1 - twin
+	return s.blocked[other] && (idle || blocked && twin == LowerTwin)
+}
+
+// Reaches reports whether the Car can stop at the floor. A Car sharing a Shaft cannot reach the floors
+// its twin needs to keep its distance at the far end.
+func (c *Car) Reaches(floor int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reaches(floor)
+}
+
+func (c *Car) reaches(floor int) bool {
+	if floor < 0 || floor >= len(c.buttons) {
+		return false
+	}
+	return c.shaft == nil || c.shaft.reaches(c.twin, floor)
+}
+
+// checkFloor returns an error wrapping ErrFloorOutOfRange if the Car cannot reach the floor.
+func (c *Car) checkFloor(floor int) error {
+	switch {
+	case floor < 0 || floor >= len(c.buttons):
+		return fmt.Errorf("%w: %d is not one of the %d floors served", ErrFloorOutOfRange, floor, len(c.buttons))
+	case !c.reaches(floor):
+		return fmt.Errorf("%w: %d is kept clear for the Car's twin", ErrFloorOutOfRange, floor)
+	}
+	return nil
+}
+
+// giveWay moves a Car that is in its twin's way one floor further from it.
+func (c *Car) giveWay(idle, blocked bool) {
+	if c.shaft == nil || !c.shaft.inTheWay(c.twin, idle, blocked) {
+		return
+	}
+	away := c.floor - 1
+	if c.twin == UpperTwin {
+		away = c.floor + 1
+	}
+	if !c.reaches(away) {
+		return
+	}
+	if idle {
+		c.updateDirection(away)
+	}
+	// a Car with calls backs off without turning, as it will carry on toward them
+	c.floor = away
+	c.status = Traveling
+}
@@ -0,0 +1,192 @@
+package floorplan
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Plan describes the floors of a building and how they are labeled.
+//
+// Everything else in the simulation refers to a floor by its index, counting from 0 at the
+// lowest level of the building. The Plan maps those indexes to the labels shown on the
+// buttons, such as B2, B1, G, M, 1, 2, ..., 12, 14.
+type Plan struct {
+	labels  []string
+	lobby   int
+	ground  int
+	parking int // the number of basement floors, counting up from the lowest, used for parking
+}
+
+// Option is a functional option type that allows us to configure the Plan.
+type Option func(*config)
+
+type config struct {
+	groundLabel  string
+	mezzanine    bool
+	skipThirteen bool
+	lobbyLabel   string
+	parking      int
+}
+
+// WithGroundLabel sets the label of the ground floor. The default is "G".
+func WithGroundLabel(label string) Option {
+	return func(c *config) {
+		c.groundLabel = label
+	}
+}
+
+// WithMezzanine adds a floor labeled "M" between the ground floor and floor 1.
+func WithMezzanine() Option {
+	return func(c *config) {
+		c.mezzanine = true
+	}
+}
+
+// WithoutThirteen numbers the floors above 12 starting with 14.
+func WithoutThirteen() Option {
+	return func(c *config) {
+		c.skipThirteen = true
+	}
+}
+
+// WithLobby sets the main lobby, the floor where passengers enter and leave the building.
+// The default is the ground floor.
+func WithLobby(label string) Option {
+	return func(c *config) {
+		c.lobbyLabel = label
+	}
+}
+
+// WithParking marks the given number of basement floors, counting up from the lowest, as parking.
+func WithParking(floors int) Option {
+	return func(c *config) {
+		c.parking = floors
+	}
+}
+
+// New creates a Plan with the given number of basement floors below the ground floor
+// and numbered floors above it.
+func New(basements, upper int, options ...Option) (*Plan, error) {
+	if basements < 0 || upper < 0 {
+		return nil, errors.New("floorplan: New requires non-negative floor counts")
+	}
+
+	cfg := config{
+		groundLabel: "G",
+	}
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	if cfg.parking < 0 || cfg.parking > basements {
+		return nil, fmt.Errorf("floorplan: cannot park on %d of %d basement floors", cfg.parking, basements)
+	}
+
+	p := Plan{
+		ground:  basements,
+		parking: cfg.parking,
+	}
+
+	for b := basements; b > 0; b-- {
+		p.labels = append(p.labels, "B"+strconv.Itoa(b))
+	}
+	p.labels = append(p.labels, cfg.groundLabel)
+	if cfg.mezzanine {
+		p.labels = append(p.labels, "M")
+	}
+	for n, added := 1, 0; added < upper; n++ {
+		if n == 13 && cfg.skipThirteen {
+			continue
+		}
+		p.labels = append(p.labels, strconv.Itoa(n))
+		added++
+	}
+
+	if err := p.checkLabels(); err != nil {
+		return nil, err
+	}
+
+	p.lobby = p.ground
+	if cfg.lobbyLabel != "" {
+		lobby, err := p.Index(cfg.lobbyLabel)
+		if err != nil {
+			return nil, err
+		}
+		p.lobby = lobby
+	}
+
+	return &p, nil
+}
+
+// Numbered creates a Plan for numFloors floors labeled 0 through numFloors-1, with the lobby on 0.
+func Numbered(numFloors int) *Plan {
+	p := Plan{}
+	for i := range numFloors {
+		p.labels = append(p.labels, strconv.Itoa(i))
+	}
+	return &p
+}
+
+// Len returns the number of floors in the Plan.
+func (p *Plan) Len() int {
+	return len(p.labels)
+}
+
+// Label returns the label of the floor at the given index.
+func (p *Plan) Label(floor int) string {
+	if floor < 0 || floor >= len(p.labels) {
+		return fmt.Sprintf("?%d", floor)
+	}
+	return p.labels[floor]
+}
+
+// Labels returns the labels of every floor, from the lowest up.
+func (p *Plan) Labels() []string {
+	return append([]string{}, p.labels...)
+}
+
+// Index returns the index of the floor with the given label.
+func (p *Plan) Index(label string) (int, error) {
+	for i, l := range p.labels {
+		if l == label {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("floorplan: no floor labeled %q", label)
+}
+
+// Lobby returns the index of the main lobby, where passengers enter and leave the building.
+func (p *Plan) Lobby() int {
+	return p.lobby
+}
+
+// Ground returns the index of the ground floor.
+func (p *Plan) Ground() int {
+	return p.ground
+}
+
+// IsParking reports whether the floor at the given index is a parking level.
+func (p *Plan) IsParking(floor int) bool {
+	return floor >= 0 && floor < p.parking
+}
+
+// Parking returns the indexes of the parking levels, from the lowest up.
+func (p *Plan) Parking() []int {
+	floors := []int{}
+	for i := range p.parking {
+		floors = append(floors, i)
+	}
+	return floors
+}
+
+func (p *Plan) checkLabels() error {
+	seen := map[string]bool{}
+	for _, l := range p.labels {
+		if seen[l] {
+			return fmt.Errorf("floorplan: label %q is used more than once", l)
+		}
+		seen[l] = true
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package floorplan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dshaneg/elevator/internal/floorplan"
+)
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		name      string
+		basements int
+		upper     int
+		options   []floorplan.Option
+		expected  []string
+	}{
+		{
+			name:     "Ground floor only",
+			upper:    0,
+			expected: []string{"G"},
+		},
+		{
+			name:      "Basements count down from the ground floor",
+			basements: 2,
+			upper:     2,
+			expected:  []string{"B2", "B1", "G", "1", "2"},
+		},
+		{
+			name:     "Mezzanine sits between the ground floor and 1",
+			upper:    2,
+			options:  []floorplan.Option{floorplan.WithMezzanine()},
+			expected: []string{"G", "M", "1", "2"},
+		},
+		{
+			name:     "Thirteen is skipped",
+			upper:    14,
+			options:  []floorplan.Option{floorplan.WithoutThirteen(), floorplan.WithGroundLabel("L")},
+			expected: []string{"L", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "14", "15"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := floorplan.New(tc.basements, tc.upper, tc.options...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, p.Labels())
+			assert.Equal(t, len(tc.expected), p.Len())
+		})
+	}
+}
+
+func TestLobbyAndParking(t *testing.T) {
+	p, err := floorplan.New(3, 5, floorplan.WithParking(2), floorplan.WithLobby("1"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, p.Ground())
+	assert.Equal(t, 4, p.Lobby())
+	assert.Equal(t, "1", p.Label(p.Lobby()))
+	assert.Equal(t, []int{0, 1}, p.Parking())
+	assert.True(t, p.IsParking(1))
+	assert.False(t, p.IsParking(2))
+
+	i, err := p.Index("B1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, i)
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		basements int
+		upper     int
+		options   []floorplan.Option
+	}{
+		{
+			name:      "Negative basements",
+			basements: -1,
+		},
+		{
+			name:      "More parking than basements",
+			basements: 1,
+			options:   []floorplan.Option{floorplan.WithParking(2)},
+		},
+		{
+			name:    "Lobby that does not exist",
+			upper:   3,
+			options: []floorplan.Option{floorplan.WithLobby("7")},
+		},
+		{
+			name:    "Ground label repeats a numbered floor",
+			upper:   3,
+			options: []floorplan.Option{floorplan.WithGroundLabel("1")},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := floorplan.New(tc.basements, tc.upper, tc.options...)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNumbered(t *testing.T) {
+	p := floorplan.Numbered(3)
+	assert.Equal(t, []string{"0", "1", "2"}, p.Labels())
+	assert.Equal(t, 0, p.Lobby())
+	assert.Equal(t, "?3", p.Label(3))
+}
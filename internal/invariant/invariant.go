@@ -0,0 +1,215 @@
+// Package invariant checks the safety and liveness properties that every bank and car of a Building should keep,
+// step after step, whatever the traffic: doors stay shut on the move, no car is overloaded or leaves the building,
+// no car turns back on calls it still has ahead, and every hall call is answered in good time.
+//
+// A Checker runs alongside a Simulation (see sim.WithChecker), so that tests fail and batch runs report
+// whenever a change to dispatch or to the cars breaks one of them.
+package invariant
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+)
+
+// DefaultHallCallBound is how long a hall call may wait for a car before it counts as unanswered.
+const DefaultHallCallBound = 30 * time.Minute
+
+// Rule is an enum type naming the invariants a Checker checks.
+type Rule int
+
+const (
+	DoorsOpenWhileTraveling Rule = iota // a car is traveling with its doors open.
+	OverCapacity                        // a car, or a deck of one, carries more passengers than it can.
+	FloorOutOfRange                     // a car is outside the floors of the Building.
+	Reversal                            // a car turned back with calls still ahead of it.
+	HallCallUnanswered                  // a hall call has waited longer than the bound.
+)
+
+var ruleNames = map[Rule]string{
+	DoorsOpenWhileTraveling: "doors open while traveling",
+	OverCapacity:            "over capacity",
+	FloorOutOfRange:         "floor out of range",
+	Reversal:                "reversed with calls ahead",
+	HallCallUnanswered:      "hall call unanswered",
+}
+
+func (r Rule) String() string {
+	if name, ok := ruleNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("Rule(%d)", int(r))
+}
+
+// Violation is a breach of one of the invariants, found at the end of a step.
+type Violation struct {
+	At     time.Time
+	Rule   Rule
+	Zone   string
+	Car    int // the index of the car in its Bank, or bank.NoCar for a hall call
+	Floor  int
+	Detail string
+}
+
+func (v Violation) String() string {
+	where := fmt.Sprintf("car %d", v.Car)
+	if v.Car == bank.NoCar {
+		where = "landing"
+	}
+	return fmt.Sprintf("%s %s %s at floor %d: %s (%s)", v.At.Format(time.DateTime), v.Zone, where, v.Floor, v.Rule, v.Detail)
+}
+
+// Checker checks the invariants of a Building each step and keeps the violations it finds.
+// The liveness invariant needs to see every step, so a Checker must be given each step of a single Building.
+type Checker struct {
+	bound      time.Duration
+	calls      map[building.Landing]time.Time // when each waiting hall call was first seen
+	reported   map[building.Landing]bool      // waiting hall calls already reported as unanswered
+	previous   map[carKey]heading             // where each car was headed at the end of the last step
+	violations []Violation
+}
+
+// heading is the direction of a car and the calls it had committed to.
+type heading struct {
+	direction car.Direction
+	calls     []int
+}
+
+// carKey identifies a car in a Building.
+type carKey struct {
+	zone  *building.Zone
+	index int
+}
+
+// Option is a functional option type that allows us to configure the Checker.
+type Option func(*Checker)
+
+// WithHallCallBound sets how long a hall call may wait for a car. The default is DefaultHallCallBound.
+func WithHallCallBound(bound time.Duration) Option {
+	return func(c *Checker) {
+		c.bound = bound
+	}
+}
+
+// New creates a Checker with no violations found.
+func New(options ...Option) *Checker {
+	c := Checker{
+		bound:    DefaultHallCallBound,
+		calls:    map[building.Landing]time.Time{},
+		reported: map[building.Landing]bool{},
+		previous: map[carKey]heading{},
+	}
+
+	for _, opt := range options {
+		opt(&c)
+	}
+
+	return &c
+}
+
+// Violations returns every violation found so far, in the order they were found.
+func (c *Checker) Violations() []Violation {
+	return c.violations
+}
+
+// Check checks the Building as it stands at the end of the step at the given time, returning the violations
+// found in this step. A hall call that outlasts the bound is reported once, however much longer it waits.
+func (c *Checker) Check(b *building.Building, now time.Time) []Violation {
+	found := []Violation{}
+	for _, z := range b.Zones() {
+		for i := range z.Bank.NumCars() {
+			found = append(found, c.checkCar(b, z, i, now)...)
+		}
+		found = append(found, c.checkCalls(z, now)...)
+	}
+	c.violations = append(c.violations, found...)
+	return found
+}
+
+// checkCar checks the safety invariants of the car at the given index.
+func (c *Checker) checkCar(b *building.Building, z *building.Zone, index int, now time.Time) []Violation {
+	m := z.Bank.Car(index)
+	floor, status, direction := m.Floor(), m.Status(), m.Direction()
+	found := []Violation{}
+	violate := func(rule Rule, format string, args ...any) {
+		found = append(found, Violation{At: now, Rule: rule, Zone: z.Name, Car: index, Floor: floor, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if status == car.Traveling && m.Door() == car.Open {
+		violate(DoorsOpenWhileTraveling, "heading %v", direction)
+	}
+	if m.Load() > m.Capacity() {
+		violate(OverCapacity, "%d aboard for %d", m.Load(), m.Capacity())
+	}
+	if d, ok := m.(bank.Decked); ok {
+		for j, deck := range d.Decks() {
+			if deck.Load() > deck.Capacity() {
+				violate(OverCapacity, "%d aboard deck %d for %d", deck.Load(), j, deck.Capacity())
+			}
+		}
+	}
+	if floor < 0 || floor >= b.NumFloors() {
+		violate(FloorOutOfRange, "the building has %d floors", b.NumFloors())
+	}
+
+	// only calls the car had before it turned count, as passengers who board afterwards may press any floor
+	key := carKey{z, index}
+	calls := m.Calls()
+	if before, ok := c.previous[key]; ok && before.direction != direction {
+		low, high := span(m)
+		for _, call := range calls {
+			ahead := before.direction == car.Up && call > high || before.direction == car.Down && call < low
+			if ahead && slices.Contains(before.calls, call) {
+				violate(Reversal, "turned %v with a call for floor %d", direction, call)
+				break
+			}
+		}
+	}
+	c.previous[key] = heading{direction, calls}
+
+	return found
+}
+
+// span returns the lowest and highest floors the car stands at: its own, or those of its decks.
+func span(m bank.Member) (low, high int) {
+	d, ok := m.(bank.Decked)
+	if !ok {
+		return m.Floor(), m.Floor()
+	}
+	decks := d.Decks()
+	return decks[0].Floor(), decks[len(decks)-1].Floor()
+}
+
+// checkCalls checks the hall calls waiting in the Zone against the bound, and forgets those that have been
+// answered or cancelled. A call counts as answered whenever a car is loading at its landing, as at a busy
+// landing the next passenger may call again in the same step the last call was answered.
+func (c *Checker) checkCalls(z *building.Zone, now time.Time) []Violation {
+	found := []Violation{}
+	for _, l := range z.Landings() {
+		assigned := z.Bank.Assigned(l.Floor, l.Direction)
+		status, _ := z.Bank.Status(l.Floor, l.Direction)
+		since, waiting := c.calls[l]
+		switch {
+		case assigned == bank.NoCar || status == bank.Loading:
+			delete(c.calls, l)
+			delete(c.reported, l)
+		case !waiting:
+			c.calls[l] = now
+		case now.Sub(since) > c.bound && !c.reported[l]:
+			c.reported[l] = true
+			found = append(found, Violation{
+				At:     now,
+				Rule:   HallCallUnanswered,
+				Zone:   z.Name,
+				Car:    bank.NoCar,
+				Floor:  l.Floor,
+				Detail: fmt.Sprintf("%v call waiting since %s, assigned to car %d", l.Direction, since.Format(time.TimeOnly), assigned),
+			})
+		}
+	}
+	return found
+}
@@ -0,0 +1,121 @@
+package invariant_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/bank/stubs"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/floorplan"
+	"github.com/dshaneg/elevator/internal/invariant"
+)
+
+var tue0800AM = time.Date(2024, 11, 19, 8, 0, 0, 0, time.Local)
+
+// newBuilding returns a ten floor building of one zone whose bank has the given car.
+func newBuilding(t *testing.T, c *stubs.Car) (*building.Building, *bank.Bank) {
+	c.CarFloors = 10
+	bk, err := bank.New(10, []bank.Member{c})
+	require.NoError(t, err)
+	b, err := building.New(floorplan.Numbered(10), building.Zone{Name: "main", Bank: bk, Floors: building.Span(0, 9)})
+	require.NoError(t, err)
+	return b, bk
+}
+
+func TestCheckCar(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   func(c *stubs.Car) // the car at the end of the step before, if it matters
+		car      func(c *stubs.Car)
+		expected []invariant.Rule
+	}{
+		{
+			name:     "Keeps every invariant",
+			car:      func(c *stubs.Car) { c.CarStatus = car.Loading; c.CarDoor = car.Open; c.CarLoad = car.DefaultCapacity },
+			expected: []invariant.Rule{},
+		},
+		{
+			name:     "Travels with its doors open",
+			car:      func(c *stubs.Car) { c.CarStatus = car.Traveling; c.CarDoor = car.Open },
+			expected: []invariant.Rule{invariant.DoorsOpenWhileTraveling},
+		},
+		{
+			name:     "Carries too many",
+			car:      func(c *stubs.Car) { c.CarLoad = car.DefaultCapacity + 1 },
+			expected: []invariant.Rule{invariant.OverCapacity},
+		},
+		{
+			name:     "Leaves the building",
+			car:      func(c *stubs.Car) { c.CarFloor = 10 },
+			expected: []invariant.Rule{invariant.FloorOutOfRange},
+		},
+		{
+			name:     "Turns back with a call ahead",
+			before:   func(c *stubs.Car) { c.CarFloor = 3; c.Pressed = []int{7} },
+			car:      func(c *stubs.Car) { c.CarFloor = 4; c.CarDirection = car.Down; c.Pressed = []int{7, 1} },
+			expected: []invariant.Rule{invariant.Reversal},
+		},
+		{
+			name:     "Turns back for a floor pressed since",
+			before:   func(c *stubs.Car) { c.CarFloor = 3 },
+			car:      func(c *stubs.Car) { c.CarFloor = 4; c.CarDirection = car.Down; c.Pressed = []int{7} },
+			expected: []invariant.Rule{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := stubs.NewCar(0)
+			b, _ := newBuilding(t, c)
+			checker := invariant.New()
+			if tc.before != nil {
+				tc.before(c)
+				assert.Empty(t, checker.Check(b, tue0800AM))
+			}
+
+			tc.car(c)
+			rules := []invariant.Rule{}
+			for _, v := range checker.Check(b, tue0800AM.Add(time.Minute)) {
+				rules = append(rules, v.Rule)
+				assert.Equal(t, "main", v.Zone)
+				assert.Equal(t, 0, v.Car)
+			}
+			assert.Equal(t, tc.expected, rules)
+			assert.Len(t, checker.Violations(), len(tc.expected))
+		})
+	}
+}
+
+func TestCheckHallCalls(t *testing.T) {
+	c := stubs.NewCar(0)
+	b, bk := newBuilding(t, c)
+	checker := invariant.New(invariant.WithHallCallBound(10 * time.Minute))
+
+	bk.Call(5, car.Up)
+	now := tue0800AM
+	for range 11 {
+		assert.Empty(t, checker.Check(b, now))
+		now = now.Add(time.Minute)
+	}
+
+	found := checker.Check(b, now)
+	require.Len(t, found, 1)
+	assert.Equal(t, invariant.HallCallUnanswered, found[0].Rule)
+	assert.Equal(t, bank.NoCar, found[0].Car)
+	assert.Equal(t, 5, found[0].Floor)
+	assert.Equal(t, "2024-11-19 08:11:00 main landing at floor 5: hall call unanswered (up call waiting since 08:00:00, assigned to car 0)", found[0].String())
+	assert.Empty(t, checker.Check(b, now.Add(time.Minute)), "reported once")
+
+	// the car gets there at last, and the next call starts afresh
+	c.CarFloor, c.CarStatus = 5, car.Loading
+	assert.Empty(t, checker.Check(b, now.Add(2*time.Minute)))
+	c.CarFloor, c.CarStatus = 0, car.Parked
+	for i := range 11 {
+		assert.Empty(t, checker.Check(b, now.Add(time.Duration(3+i)*time.Minute)))
+	}
+}
@@ -0,0 +1,194 @@
+// Package metrics exports the state of a Simulation in the Prometheus text exposition format, so that
+// existing dashboards can be pointed at a simulated building.
+//
+// Cars are labelled by zone and index, and landings by zone, floor label and direction. Floors are
+// reported as gauges counting up from 0 at the lowest level, as cars are.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/sim"
+)
+
+// ContentType is the media type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var statuses = []car.Status{car.Parked, car.Loading, car.Traveling}
+
+// Write writes the metrics of the Simulation to w. The wait time histogram has the buckets of sim.WaitBuckets.
+func Write(w io.Writer, s *sim.Simulation) error {
+	e := encoder{w: bufio.NewWriter(w)}
+	b := s.Building()
+	waits := s.WaitHistogram()
+
+	e.family("simuvator_time_seconds", "gauge", "The simulated time, in seconds since the Unix epoch.")
+	e.sample("simuvator_time_seconds", nil, float64(s.Now().Unix()))
+
+	e.family("simuvator_car_floor", "gauge", "The floor each car is at, counting up from 0 at the lowest level.")
+	eachCar(b, func(z *building.Zone, i int, c labels) {
+		e.sample("simuvator_car_floor", c, float64(z.Bank.Car(i).Floor()))
+	})
+
+	e.family("simuvator_car_load", "gauge", "Passengers aboard each car.")
+	eachCar(b, func(z *building.Zone, i int, c labels) {
+		e.sample("simuvator_car_load", c, float64(z.Bank.Car(i).Load()))
+	})
+
+	e.family("simuvator_car_capacity", "gauge", "The most passengers each car can carry.")
+	eachCar(b, func(z *building.Zone, i int, c labels) {
+		e.sample("simuvator_car_capacity", c, float64(z.Bank.Car(i).Capacity()))
+	})
+
+	e.family("simuvator_car_status", "gauge", "1 for the status each car is in, 0 for the others.")
+	eachCar(b, func(z *building.Zone, i int, c labels) {
+		for _, status := range statuses {
+			e.sample("simuvator_car_status", append(c, label{"status", status.String()}), boolValue(z.Bank.Car(i).Status() == status))
+		}
+	})
+
+	e.family("simuvator_car_in_service", "gauge", "1 if the car is working in normal or attendant service, 0 if it is not.")
+	eachCar(b, func(z *building.Zone, i int, c labels) {
+		m := z.Bank.Car(i)
+		e.sample("simuvator_car_in_service", c, boolValue(m.Mode().Dispatchable() && m.Fault() == car.NoFault))
+	})
+
+	e.family("simuvator_hall_call_pending", "gauge", "1 if a hall call is waiting for a car at the landing, 0 if not.")
+	eachLanding(b, func(l building.Landing, ls labels) {
+		status, _ := l.Zone.Bank.Status(l.Floor, l.Direction)
+		e.sample("simuvator_hall_call_pending", ls, boolValue(status == bank.Waiting))
+	})
+
+	e.family("simuvator_landing_passengers_waiting", "gauge", "Passengers waiting at each landing.")
+	eachLanding(b, func(l building.Landing, ls labels) {
+		e.sample("simuvator_landing_passengers_waiting", ls, float64(b.Waiting(l)))
+	})
+
+	e.family("simuvator_passengers_waiting", "gauge", "Passengers waiting for a car anywhere in the building.")
+	e.sample("simuvator_passengers_waiting", nil, float64(s.Waiting()))
+
+	e.family("simuvator_passengers", "gauge", "Passengers simulated: the regular occupants, wherever they are, and visitors yet to finish their trips.")
+	e.sample("simuvator_passengers", nil, float64(len(s.Passengers())))
+
+	e.family("simuvator_rides_total", "counter", "Rides boarded; a trip with a transfer is two rides.")
+	e.sample("simuvator_rides_total", nil, float64(waits.Count))
+
+	e.family("simuvator_abandoned_waits_total", "counter", "Waits that ended without boarding.")
+	e.sample("simuvator_abandoned_waits_total", nil, float64(s.Abandoned()))
+
+	e.family("simuvator_wait_seconds", "histogram", "How long passengers who boarded a car waited for it.")
+	for i, bound := range sim.WaitBuckets {
+		e.sample("simuvator_wait_seconds_bucket", labels{{"le", formatValue(bound.Seconds())}}, float64(waits.Buckets[i]))
+	}
+	e.sample("simuvator_wait_seconds_bucket", labels{{"le", "+Inf"}}, float64(waits.Count))
+	e.sample("simuvator_wait_seconds_sum", nil, waits.Sum.Seconds())
+	e.sample("simuvator_wait_seconds_count", nil, float64(waits.Count))
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// Handler serves the metrics of a Simulation. Inspect is called at each scrape to get at the Simulation
+// while nothing else is using it, as session.Session.Inspect does.
+func Handler(inspect func(func(*sim.Simulation))) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf strings.Builder
+		var err error
+		inspect(func(s *sim.Simulation) {
+			err = Write(&buf, s)
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		io.WriteString(w, buf.String())
+	})
+}
+
+func eachCar(b *building.Building, f func(z *building.Zone, i int, c labels)) {
+	for _, z := range b.Zones() {
+		for i := range z.Bank.NumCars() {
+			f(z, i, labels{{"zone", z.Name}, {"car", strconv.Itoa(i)}})
+		}
+	}
+}
+
+func eachLanding(b *building.Building, f func(l building.Landing, ls labels)) {
+	for _, z := range b.Zones() {
+		for _, l := range z.Landings() {
+			f(l, labels{{"zone", z.Name}, {"floor", b.Plan().Label(l.Floor)}, {"direction", l.Direction.String()}})
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type label struct {
+	name, value string
+}
+
+type labels []label
+
+// encoder writes metric families in the text exposition format, remembering the first error.
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) family(name, kind, help string) {
+	e.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (e *encoder) sample(name string, ls labels, value float64) {
+	var sb strings.Builder
+	sb.WriteString(name)
+	if len(ls) > 0 {
+		sb.WriteByte('{')
+		for i, l := range ls {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			fmt.Fprintf(&sb, `%s="%s"`, l.name, labelEscaper.Replace(l.value))
+		}
+		sb.WriteByte('}')
+	}
+	e.printf("%s %s\n", sb.String(), formatValue(value))
+}
+
+func (e *encoder) printf(format string, args ...any) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
+
+// labelEscaper escapes the characters the text format does not allow in a label value.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
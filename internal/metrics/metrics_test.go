@@ -0,0 +1,124 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/floorplan"
+	"github.com/dshaneg/elevator/internal/metrics"
+	"github.com/dshaneg/elevator/internal/passenger"
+	"github.com/dshaneg/elevator/internal/sim"
+)
+
+// tue0800AM is the start of a working day.
+var tue0800AM = time.Date(2024, 11, 19, 8, 0, 0, 0, time.UTC)
+
+func newSimulation(t *testing.T) *sim.Simulation {
+	cars := []bank.Member{}
+	for range 2 {
+		c, err := car.NewCar(10)
+		require.NoError(t, err)
+		cars = append(cars, c)
+	}
+	bk, err := bank.New(10, cars)
+	require.NoError(t, err)
+
+	b, err := building.New(floorplan.Numbered(10),
+		building.Zone{Name: "main", Bank: bk, Floors: building.Span(0, 9)},
+	)
+	require.NoError(t, err)
+
+	lobby, err := passenger.New(b, passenger.WithPrimaryFloor(3))
+	require.NoError(t, err)
+	upstairs, err := passenger.New(b, passenger.WithPrimaryFloor(5), passenger.WithFloor(9))
+	require.NoError(t, err)
+	s, err := sim.New(b, []*passenger.Passenger{lobby, upstairs}, sim.WithStart(tue0800AM.Add(-time.Minute)))
+	require.NoError(t, err)
+	return s
+}
+
+func scrape(t *testing.T, s *sim.Simulation) string {
+	var sb strings.Builder
+	require.NoError(t, metrics.Write(&sb, s))
+	return sb.String()
+}
+
+func TestWrite(t *testing.T) {
+	s := newSimulation(t)
+
+	// the lobby passenger waits a step for the doors to open, while the other waits for a car to climb to 9
+	s.Run(tue0800AM.Add(time.Hour))
+	out := scrape(t, s)
+
+	for _, line := range []string{
+		"# HELP simuvator_car_floor The floor each car is at, counting up from 0 at the lowest level.",
+		"# TYPE simuvator_car_floor gauge",
+		`simuvator_time_seconds 1732006800`, // 09:00 UTC
+		`simuvator_car_status{zone="main",car="0",status="parked"} 1`,
+		`simuvator_car_status{zone="main",car="0",status="loading"} 0`,
+		`simuvator_car_load{zone="main",car="1"} 0`,
+		`simuvator_car_capacity{zone="main",car="1"} 16`,
+		`simuvator_car_in_service{zone="main",car="1"} 1`,
+		`simuvator_hall_call_pending{zone="main",floor="9",direction="down"} 0`,
+		`simuvator_landing_passengers_waiting{zone="main",floor="0",direction="up"} 0`,
+		`simuvator_passengers_waiting 0`,
+		"# HELP simuvator_passengers Passengers simulated: the regular occupants, wherever they are, and visitors yet to finish their trips.",
+		`simuvator_passengers 2`,
+		`simuvator_rides_total 2`,
+		"# TYPE simuvator_wait_seconds histogram",
+		`simuvator_wait_seconds_bucket{le="60"} 1`,
+		`simuvator_wait_seconds_bucket{le="300"} 1`,
+		`simuvator_wait_seconds_bucket{le="600"} 2`,
+		`simuvator_wait_seconds_bucket{le="+Inf"} 2`,
+		`simuvator_wait_seconds_sum 600`,
+		`simuvator_wait_seconds_count 2`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+
+	// no landing below the lowest floor or above the highest
+	assert.NotContains(t, out, `floor="0",direction="down"`)
+	assert.NotContains(t, out, `floor="9",direction="up"`)
+}
+
+func TestWriteWhileWaiting(t *testing.T) {
+	s := newSimulation(t)
+	b := s.Building()
+	z, _ := b.Zone("main")
+	l := building.Landing{Zone: z, Floor: 6, Direction: car.Down}
+	b.Join(l)
+	b.Join(l)
+	z.Bank.Call(6, car.Down)
+
+	out := scrape(t, s)
+
+	assert.Contains(t, out, `simuvator_hall_call_pending{zone="main",floor="6",direction="down"} 1`+"\n")
+	assert.Contains(t, out, `simuvator_landing_passengers_waiting{zone="main",floor="6",direction="down"} 2`+"\n")
+	assert.Contains(t, out, `simuvator_hall_call_pending{zone="main",floor="6",direction="up"} 0`+"\n")
+}
+
+func TestHandler(t *testing.T) {
+	s := newSimulation(t)
+	inspected := 0
+	h := metrics.Handler(func(f func(*sim.Simulation)) {
+		inspected++
+		f(s)
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, metrics.ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, 1, inspected)
+	assert.Equal(t, scrape(t, s), rec.Body.String())
+}
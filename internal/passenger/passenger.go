@@ -1,39 +1,80 @@
 package passenger
 
 import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
 	"time"
 
+	"github.com/dshaneg/elevator/internal/building"
 	"github.com/dshaneg/elevator/internal/elevator/bank"
 	"github.com/dshaneg/elevator/internal/elevator/car"
 )
 
 // Passenger represents a person who rides our elevators.
 type Passenger struct {
-	bank         *bank.Bank
+	building     *building.Building
 	primaryFloor int
+	homeFloor    int // where the Passenger enters and leaves the building
 	shift        Shift
 	floor        int
 	status       Status
 	destFloor    int
+	legs         []building.Leg // the rides remaining on the way to destFloor, starting with the current one
 	car          bank.Member
+
+	patience  Patience
+	rand      *rand.Rand
+	stats     Stats
+	landing   *building.Landing // where the Passenger is waiting, if they are
+	giveUpAt  time.Time         // when the Passenger stops waiting for a car, or zero to wait forever
+	restUntil time.Time         // when a Passenger who balked or gave up tries again
+
+	oneOff    bool // whether the Passenger makes a single trip rather than keeping a shift
+	tripFloor int  // where a one-off Passenger is going
 }
 
 // Option is a functional option type that allows us to configure the Passenger.
 type Option func(*Passenger)
 
-// New creates a new Passenger with the given primary floor and options.
-func New(b *bank.Bank, options ...Option) *Passenger {
+// New creates a new Passenger who rides the elevators of the given Building. It returns an error wrapping
+// bank.ErrFloorOutOfRange if any floor given in the options is not in the Building.
+func New(b *building.Building, options ...Option) (*Passenger, error) {
 	p := Passenger{
-		bank:   b,
-		shift:  DefaultShift,
-		status: Idle,
+		building:  b,
+		homeFloor: b.Plan().Lobby(),
+		floor:     b.Plan().Lobby(),
+		shift:     DefaultShift,
+		status:    Idle,
+		rand:      rand.New(rand.NewPCG(0, 0)),
 	}
 
 	for _, opt := range options {
 		opt(&p)
 	}
 
-	return &p
+	if err := p.checkFloors(); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// checkFloors returns an error wrapping bank.ErrFloorOutOfRange for each floor the Passenger was given
+// that is not in the Building.
+func (p *Passenger) checkFloors() error {
+	check := func(name string, floor int) error {
+		if floor < 0 || floor >= p.building.NumFloors() {
+			return fmt.Errorf("%w: %s %d is not one of the %d floors in the Building", bank.ErrFloorOutOfRange, name, floor, p.building.NumFloors())
+		}
+		return nil
+	}
+
+	err := errors.Join(check("primary floor", p.primaryFloor), check("home floor", p.homeFloor), check("floor", p.floor))
+	if p.oneOff {
+		err = errors.Join(err, check("trip floor", p.tripFloor))
+	}
+	return err
 }
 
 func WithPrimaryFloor(floor int) Option {
@@ -42,6 +83,15 @@ func WithPrimaryFloor(floor int) Option {
 	}
 }
 
+// WithHomeFloor sets the floor where the Passenger enters and leaves the building,
+// such as a parking level for those who drive. The default is the main lobby.
+func WithHomeFloor(floor int) Option {
+	return func(p *Passenger) {
+		p.homeFloor = floor
+		p.floor = floor
+	}
+}
+
 func WithShift(s Shift) Option {
 	return func(p *Passenger) {
 		p.shift = s
@@ -54,6 +104,15 @@ func WithFloor(floor int) Option {
 	}
 }
 
+// WithTrip makes the Passenger a visitor who makes a single trip to the given floor, starting straight away
+// whatever their shift, and is Arrived once they get there.
+func WithTrip(dest int) Option {
+	return func(p *Passenger) {
+		p.oneOff = true
+		p.tripFloor = dest
+	}
+}
+
 func WithStatus(status Status) Option {
 	return func(p *Passenger) {
 		p.status = status
@@ -73,56 +132,237 @@ func (p *Passenger) Tick(simTime time.Time) {
 	// at the transition to Idle or Active, we should determine the time
 	// and destination for the next elevator ride and queue it up
 
+	if p.status == Arrived {
+		return
+	}
+	if p.building.InFireService() {
+		p.evacuate()
+		return
+	}
+	if p.status == Evacuated {
+		// the all clear has sounded, so pick up the day from the lobby
+		p.status = Idle
+	}
+
 	isInShift := p.shift.IsInShift(simTime)
 	switch {
-	// coming to work
-	// Idle -> WaitingUp or WaitingDown
-	case p.status == Idle && isInShift && p.floor != p.primaryFloor:
-		p.destFloor = p.primaryFloor
-		p.call(p.destFloor)
-	// done for the day
-	// Active -> WaitingUp or WaitingDown
-	case p.status == Active && !isInShift && p.floor != 0:
-		p.destFloor = 0
-		p.call(p.destFloor)
+	// putting off a trip after balking or giving up
+	case (p.status == Idle || p.status == Active) && simTime.Before(p.restUntil):
+	// coming to work, done for the day, or setting off on a one-off trip
+	// Idle or Active -> WaitingUp or WaitingDown
+	case (p.status == Idle || p.status == Active) && p.floor != p.target(isInShift):
+		p.travel(p.target(isInShift), simTime, isInShift)
+	// giving up on the elevators
+	// WaitingUp or WaitingDown -> Idle or Active
+	case (p.status == WaitingUp || p.status == WaitingDown) && !p.giveUpAt.IsZero() && !simTime.Before(p.giveUpAt):
+		p.leave(false)
+		p.stats.Reneged++
+		p.legs = nil
+		p.restUntil = simTime.Add(p.patience.Retry)
+		p.status = p.resting(isInShift)
 	// going up or going down
 	// WaitingUp or WaitingDown -> Riding
 	case p.status == WaitingUp || p.status == WaitingDown:
-		p.ride()
+		p.ride(simTime)
 	// exiting elevator
-	// Riding -> Idle or Active
-	case p.status == Riding && p.car.Floor() == p.destFloor && p.car.Status() == car.Loading:
+	// Riding -> WaitingUp or WaitingDown at a transfer floor, otherwise Idle or Active
+	case p.status == Riding && p.car.Floor() == p.legs[0].To && p.car.Status() == car.Loading:
+		p.car.Exit()
 		p.car = nil
-		if isInShift {
-			p.status = Active
+		p.floor = p.legs[0].To
+		p.legs = p.legs[1:]
+		if len(p.legs) > 0 {
+			p.call(simTime)
 		} else {
-			p.status = Idle
+			// across the lobby, if the car stopped at its other level
+			p.floor = p.destFloor
+			p.status = p.resting(isInShift)
 		}
 	}
 }
 
-func (p *Passenger) ride() {
+// target returns the floor the Passenger wants to be on: the end of their trip, or else their primary floor
+// during their shift and their home floor outside it.
+func (p *Passenger) target(isInShift bool) int {
+	switch {
+	case p.oneOff:
+		return p.tripFloor
+	case isInShift:
+		return p.primaryFloor
+	default:
+		return p.homeFloor
+	}
+}
+
+// resting returns the status of a Passenger who is not on their way anywhere.
+func (p *Passenger) resting(isInShift bool) Status {
+	switch {
+	case p.oneOff && p.floor == p.tripFloor:
+		return Arrived
+	case p.oneOff || !isInShift:
+		return Idle
+	default:
+		return Active
+	}
+}
+
+// evacuate stops the Passenger from calling cars and sends them down the stairs and out through the lobby.
+// A Passenger who is riding stays aboard until the car opens its doors.
+func (p *Passenger) evacuate() {
+	switch p.status {
+	case Idle, Evacuated:
+		return
+	case Riding:
+		if p.car.Door() != car.Open {
+			return
+		}
+		p.car.Exit()
+		p.car = nil
+	case WaitingUp, WaitingDown:
+		p.leave(false)
+	}
+
+	p.legs = nil
+	p.floor = p.building.Plan().Lobby()
+	p.status = Evacuated
+}
+
+// Car returns the car the Passenger is riding, or nil if they are not riding.
+func (p *Passenger) Car() bank.Member {
+	return p.car
+}
+
+// Destination returns the floor the Passenger is currently headed to.
+func (p *Passenger) Destination() int {
+	return p.destFloor
+}
+
+// Legs returns the rides remaining on the Passenger's current trip, starting with the current one.
+func (p *Passenger) Legs() []building.Leg {
+	return p.legs
+}
+
+// travel plans a trip to the given floor and calls a car for the first leg.
+// If the Building has no route to the floor, the Passenger stays put.
+//
+// A short enough trip may be walked by the stairs instead, and a Passenger who finds the first landing
+// too crowded puts the trip off; see [Patience].
+func (p *Passenger) travel(dest int, now time.Time, isInShift bool) {
+	if p.takesStairs(abs(dest - p.floor)) {
+		p.floor = dest
+		p.stats.StairTrips++
+		p.status = p.resting(isInShift)
+		return
+	}
+
+	legs, err := p.building.Route(p.floor, dest)
+	if err != nil || len(legs) == 0 {
+		return
+	}
+
+	if p.patience.Crowd > 0 && p.building.Waiting(landingFor(legs[0], legs[0].From)) >= p.patience.Crowd {
+		p.stats.Balked++
+		p.restUntil = now.Add(p.patience.Retry)
+		return
+	}
+
+	p.destFloor = dest
+	p.legs = legs
+	p.call(now)
+}
+
+func (p *Passenger) ride(now time.Time) {
 	direction := car.Down
 	if p.status == WaitingUp {
 		direction = car.Up
 	}
 	// one of the cars in the bank may be loading, but headed the wrong direction
 	// so we need to check the status in the direction we want to go
-	status, c := p.bank.Status(p.floor, direction)
-	if status == bank.Loading {
-		p.status = Riding
-		p.car = c
-		p.car.Call(p.destFloor)
+	bk := p.legs[0].Zone.Bank
+	status, c := bk.Status(p.floor, direction)
+	if status == bank.Idle && p.press(direction, now) {
+		// no car had our call, perhaps because one answered it and was just closing its doors,
+		// so we pressed the button again and the doors opened
+		status, c = bk.Status(p.floor, direction)
+	}
+	if status != bank.Loading || !c.Reaches(p.legs[0].To) {
+		// a car that cannot take the Passenger where they are going, such as the wrong one of twins,
+		// is left to go without them
+		return
 	}
+
+	if !c.Enter() {
+		// the car is full, so wait for the next one
+		return
+	}
+	if _, err := c.Press(p.legs[0].To); err != nil {
+		// the car cannot take the Passenger after all, so they step back off and wait for another
+		c.Exit()
+		return
+	}
+	p.leave(true)
+	p.status = Riding
+	p.car = c
 }
 
-func (p *Passenger) call(dest int) {
-	if p.floor < dest {
-		p.bank.Call(p.floor, car.Up)
+// call joins the landing for the current leg of the trip and calls a car. The leg may start on the other
+// level of a lobby, which the Passenger walks to first.
+func (p *Passenger) call(now time.Time) {
+	p.floor = p.legs[0].From
+	l := landingFor(p.legs[0], p.floor)
+	p.landing = &l
+	p.building.Join(l)
+	p.giveUpAt = p.waitUntil(now)
+
+	p.status = WaitingDown
+	if l.Direction == car.Up {
 		p.status = WaitingUp
+	}
+	p.press(l.Direction, now)
+}
+
+// press calls a car for where the Passenger is going, unless a car headed the right way is closing its doors
+// at the landing, in which case the Passenger stops them to get on. It returns true if the doors were reopened.
+// A Passenger whose call the Bank turns down gives up at the next step rather than wait for a car that will
+// never come.
+func (p *Passenger) press(direction car.Direction, now time.Time) bool {
+	bk := p.legs[0].Zone.Bank
+	if bk.Reopen(p.floor, direction) {
+		return true
+	}
+	if _, err := bk.CallFor(p.floor, direction, p.legs[0].To); err != nil {
+		p.giveUpAt = now
+	}
+	return false
+}
+
+// leave takes the Passenger away from the landing they were waiting at. The last Passenger to walk away
+// without boarding cancels the hall call.
+func (p *Passenger) leave(boarding bool) {
+	if p.landing == nil {
 		return
 	}
 
-	p.bank.Call(p.floor, car.Down)
-	p.status = WaitingDown
+	l := *p.landing
+	p.landing = nil
+	p.giveUpAt = time.Time{}
+	if p.building.Leave(l) == 0 && !boarding {
+		l.Zone.Bank.Cancel(l.Floor, l.Direction)
+	}
+}
+
+// landingFor returns the landing a Passenger on the given floor waits at for the leg.
+func landingFor(leg building.Leg, floor int) building.Landing {
+	direction := car.Down
+	if leg.From < leg.To {
+		direction = car.Up
+	}
+	return building.Landing{Zone: leg.Zone, Floor: floor, Direction: direction}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
 }
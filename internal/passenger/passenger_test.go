@@ -0,0 +1,255 @@
+package passenger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/floorplan"
+	"github.com/dshaneg/elevator/internal/passenger"
+)
+
+func newBank(t *testing.T, numFloors int) *bank.Bank {
+	c, err := car.NewCar(numFloors)
+	require.NoError(t, err)
+	b, err := bank.New(numFloors, []bank.Member{c})
+	require.NoError(t, err)
+	return b
+}
+
+// newPassenger creates a Passenger whose floors are all in the Building.
+func newPassenger(t *testing.T, b *building.Building, options ...passenger.Option) *passenger.Passenger {
+	p, err := passenger.New(b, options...)
+	require.NoError(t, err)
+	return p
+}
+
+func TestPassengerTransfersAtSkyLobby(t *testing.T) {
+	const numFloors = 12
+	b, err := building.New(floorplan.Numbered(numFloors),
+		building.Zone{Name: "low-rise", Bank: newBank(t, numFloors), Floors: building.Span(0, 5)},
+		building.Zone{Name: "shuttle", Bank: newBank(t, numFloors), Floors: []int{0, 6}},
+		building.Zone{Name: "high-rise", Bank: newBank(t, numFloors), Floors: building.Span(6, 11)},
+	)
+	assert.NoError(t, err)
+
+	p := newPassenger(t, b, passenger.WithPrimaryFloor(9))
+
+	// step through a Tuesday morning until the passenger reaches their desk
+	simTime := time.Date(2024, 11, 19, 8, 0, 0, 0, time.Local)
+	zones := []string{}
+	for range 100 {
+		b.Tick()
+		p.Tick(simTime)
+		simTime = simTime.Add(time.Minute)
+
+		if p.Status() == passenger.WaitingUp && (len(zones) == 0 || zones[len(zones)-1] != p.Legs()[0].Zone.Name) {
+			zones = append(zones, p.Legs()[0].Zone.Name)
+		}
+		if p.Status() == passenger.Active {
+			break
+		}
+	}
+
+	assert.Equal(t, passenger.Active, p.Status())
+	assert.Equal(t, 9, p.Floor())
+	assert.Equal(t, []string{"shuttle", "high-rise"}, zones)
+}
+
+func TestPassengerLeavesFromParking(t *testing.T) {
+	plan, err := floorplan.New(2, 5, floorplan.WithParking(2))
+	assert.NoError(t, err)
+	b, err := building.New(plan,
+		building.Zone{Name: "main", Bank: newBank(t, plan.Len()), Floors: building.Span(0, plan.Len()-1)},
+	)
+	assert.NoError(t, err)
+
+	parking, err := plan.Index("B2")
+	assert.NoError(t, err)
+	office, err := plan.Index("4")
+	assert.NoError(t, err)
+
+	p := newPassenger(t, b, passenger.WithPrimaryFloor(office), passenger.WithHomeFloor(parking))
+	assert.Equal(t, parking, p.Floor())
+
+	// ride up in the morning and back down to the car in the evening
+	simTime := time.Date(2024, 11, 19, 7, 59, 0, 0, time.Local)
+	for range 12 * 60 {
+		b.Tick()
+		p.Tick(simTime)
+		simTime = simTime.Add(time.Minute)
+	}
+
+	assert.Equal(t, passenger.Idle, p.Status())
+	assert.Equal(t, parking, p.Floor())
+}
+
+// newTower returns a single zone building whose one car waits at the top floor.
+func newTower(t *testing.T, numFloors int) (*building.Building, *bank.Bank) {
+	c, err := car.NewCar(numFloors, car.WithFloor(numFloors-1))
+	require.NoError(t, err)
+	bk, err := bank.New(numFloors, []bank.Member{c})
+	assert.NoError(t, err)
+	b, err := building.New(floorplan.Numbered(numFloors),
+		building.Zone{Name: "main", Bank: bk, Floors: building.Span(0, numFloors-1)},
+	)
+	assert.NoError(t, err)
+	return b, bk
+}
+
+var tue0800AM = time.Date(2024, 11, 19, 8, 0, 0, 0, time.Local)
+
+func TestPassengerTakesTheStairs(t *testing.T) {
+	b, _ := newTower(t, 10)
+	patience := passenger.Patience{StairFloors: 2, StairChance: 1}
+
+	walker := newPassenger(t, b, passenger.WithPrimaryFloor(2), passenger.WithPatience(patience))
+	walker.Tick(tue0800AM)
+	assert.Equal(t, passenger.Active, walker.Status())
+	assert.Equal(t, 2, walker.Floor())
+	assert.Equal(t, passenger.Stats{StairTrips: 1}, walker.Stats())
+
+	rider := newPassenger(t, b, passenger.WithPrimaryFloor(3), passenger.WithPatience(patience))
+	rider.Tick(tue0800AM)
+	assert.Equal(t, passenger.WaitingUp, rider.Status(), "three floors is too far to walk")
+}
+
+func TestPassengerReneges(t *testing.T) {
+	b, bk := newTower(t, 10)
+	patience := passenger.Patience{Mean: time.Nanosecond, Retry: 10 * time.Minute}
+	p := newPassenger(t, b, passenger.WithPrimaryFloor(5), passenger.WithPatience(patience))
+
+	p.Tick(tue0800AM)
+	assert.Equal(t, passenger.WaitingUp, p.Status())
+
+	// the car is still on its way down when the passenger gives up
+	b.Tick()
+	p.Tick(tue0800AM.Add(time.Minute))
+	assert.Equal(t, passenger.Active, p.Status())
+	assert.Equal(t, 0, p.Floor())
+	assert.Equal(t, passenger.Stats{Reneged: 1}, p.Stats())
+
+	status, _ := bk.Status(0, car.Up)
+	assert.Equal(t, bank.Idle, status, "the last passenger to leave cancels the hall call")
+
+	// and tries again once the retry time has passed
+	p.Tick(tue0800AM.Add(5 * time.Minute))
+	assert.Equal(t, passenger.Active, p.Status())
+	p.Tick(tue0800AM.Add(11 * time.Minute))
+	assert.Equal(t, passenger.WaitingUp, p.Status())
+}
+
+func TestHallCallOutlastsImpatientPassengers(t *testing.T) {
+	b, bk := newTower(t, 10)
+	impatient := newPassenger(t, b, passenger.WithPrimaryFloor(5), passenger.WithPatience(passenger.Patience{Mean: time.Nanosecond}))
+	patient := newPassenger(t, b, passenger.WithPrimaryFloor(6))
+
+	impatient.Tick(tue0800AM)
+	patient.Tick(tue0800AM)
+	impatient.Tick(tue0800AM.Add(time.Minute))
+	assert.Equal(t, passenger.Active, impatient.Status())
+
+	status, _ := bk.Status(0, car.Up)
+	assert.Equal(t, bank.Waiting, status)
+}
+
+func TestPassengerBalksAtACrowd(t *testing.T) {
+	b, _ := newTower(t, 10)
+	z, _ := b.Zone("main")
+	crowd := building.Landing{Zone: z, Floor: 0, Direction: car.Up}
+	b.Join(crowd)
+	b.Join(crowd)
+
+	patience := passenger.Patience{Crowd: 2, Retry: 10 * time.Minute}
+	p := newPassenger(t, b, passenger.WithPrimaryFloor(5), passenger.WithPatience(patience))
+
+	p.Tick(tue0800AM)
+	assert.Equal(t, passenger.Idle, p.Status())
+	assert.Equal(t, passenger.Stats{Balked: 1}, p.Stats())
+
+	b.Leave(crowd)
+	p.Tick(tue0800AM.Add(10 * time.Minute))
+	assert.Equal(t, passenger.WaitingUp, p.Status())
+	assert.Equal(t, 2, b.Waiting(crowd))
+}
+
+func TestPassengerMakesAOneOffTrip(t *testing.T) {
+	b, _ := newTower(t, 10)
+
+	// a visitor turns up at night, long after any shift
+	simTime := time.Date(2024, 11, 19, 23, 0, 0, 0, time.Local)
+	p := newPassenger(t, b, passenger.WithFloor(7), passenger.WithTrip(2))
+	for range 20 {
+		b.Tick()
+		p.Tick(simTime)
+		simTime = simTime.Add(time.Minute)
+	}
+
+	assert.Equal(t, passenger.Arrived, p.Status())
+	assert.Equal(t, 2, p.Floor())
+}
+
+func TestPassengerRidesADoubleDeck(t *testing.T) {
+	const numFloors = 10
+	d, err := car.NewDoubleDeck(numFloors, 0)
+	require.NoError(t, err)
+	bk, err := bank.New(numFloors, []bank.Member{d})
+	assert.NoError(t, err)
+	b, err := building.New(floorplan.Numbered(numFloors),
+		building.Zone{Name: "main", Bank: bk, Floors: building.Span(0, numFloors-1), Lobbies: []int{0, 1}},
+	)
+	assert.NoError(t, err)
+
+	p := newPassenger(t, b, passenger.WithPrimaryFloor(7))
+	p.Tick(tue0800AM)
+	assert.Equal(t, passenger.WaitingUp, p.Status())
+	assert.Equal(t, 1, p.Floor(), "an odd floor is reached from the upper level of the lobby")
+
+	simTime := tue0800AM
+	for range 20 {
+		b.Tick()
+		simTime = simTime.Add(time.Minute)
+		p.Tick(simTime)
+		if p.Status() == passenger.Riding {
+			assert.Same(t, d.Decks()[1], p.Car())
+		}
+	}
+	assert.Equal(t, passenger.Active, p.Status())
+	assert.Equal(t, 7, p.Floor())
+
+	// and back down in the evening, off the upper deck and across the lobby
+	simTime = time.Date(2024, 11, 19, 17, 0, 0, 0, time.Local)
+	for range 20 {
+		b.Tick()
+		simTime = simTime.Add(time.Minute)
+		p.Tick(simTime)
+	}
+	assert.Equal(t, passenger.Idle, p.Status())
+	assert.Equal(t, 0, p.Floor())
+}
+
+func TestNewRejectsFloorsOutOfRange(t *testing.T) {
+	b, _ := newTower(t, 10)
+	tests := []struct {
+		name    string
+		options []passenger.Option
+	}{
+		{name: "Primary floor", options: []passenger.Option{passenger.WithPrimaryFloor(10)}},
+		{name: "Home floor", options: []passenger.Option{passenger.WithHomeFloor(-1)}},
+		{name: "Floor", options: []passenger.Option{passenger.WithFloor(12)}},
+		{name: "Trip floor", options: []passenger.Option{passenger.WithTrip(10)}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := passenger.New(b, tc.options...)
+			assert.ErrorIs(t, err, bank.ErrFloorOutOfRange)
+			assert.Nil(t, p)
+		})
+	}
+}
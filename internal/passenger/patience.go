@@ -0,0 +1,70 @@
+package passenger
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Patience describes when a Passenger won't wait for a car at all, and how long they wait before giving up.
+// The zero Patience always rides, and waits however long it takes.
+type Patience struct {
+	StairFloors int           // trips of at most this many floors may be walked instead
+	StairChance float64       // the chance, from 0 to 1, that a Passenger walks a short trip
+	Crowd       int           // a Passenger balks at a landing where this many are already waiting; 0 never balks
+	Mean        time.Duration // the mean time a Passenger waits for a car before giving up; 0 waits forever
+	Retry       time.Duration // how long a Passenger who balked or gave up leaves it before trying again
+}
+
+// DefaultPatience is an office worker who will walk a floor or two now and then, and won't wait long in a crowd.
+var DefaultPatience = Patience{
+	StairFloors: 2,
+	StairChance: 0.3,
+	Crowd:       12,
+	Mean:        3 * time.Minute,
+	Retry:       5 * time.Minute,
+}
+
+// Stats counts the times a Passenger did without the elevators.
+type Stats struct {
+	StairTrips int // trips walked by the stairs
+	Balked     int // trips put off because the landing was too crowded
+	Reneged    int // waits given up on before a car came
+}
+
+// WithPatience sets the Passenger's Patience.
+func WithPatience(pt Patience) Option {
+	return func(p *Passenger) {
+		p.patience = pt
+	}
+}
+
+// WithRand sets the source of the random draws that decide when the Passenger takes the stairs and how long
+// they wait. Passengers sharing a source give a different, but still repeatable, mix of behaviour.
+// By default each Passenger has its own source with a fixed seed.
+func WithRand(r *rand.Rand) Option {
+	return func(p *Passenger) {
+		p.rand = r
+	}
+}
+
+// Stats returns how often the Passenger has done without the elevators.
+func (p *Passenger) Stats() Stats {
+	return p.stats
+}
+
+// takesStairs decides whether the Passenger walks a trip of the given number of floors.
+func (p *Passenger) takesStairs(floors int) bool {
+	if floors > p.patience.StairFloors || p.patience.StairChance == 0 {
+		return false
+	}
+	return p.rand.Float64() < p.patience.StairChance
+}
+
+// waitUntil samples how long the Passenger will wait for a car that was called at the given time,
+// returning the zero time if they will wait forever.
+func (p *Passenger) waitUntil(now time.Time) time.Time {
+	if p.patience.Mean == 0 {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(p.rand.ExpFloat64() * float64(p.patience.Mean)))
+}
@@ -11,4 +11,6 @@ const (
 	WaitingDown               // waiting at the elevator landing to go down.
 	WaitingUp                 // waiting at the elevator landing to go up.
 	Riding                    // riding in an elevator car.
+	Evacuated                 // left the building by the stairs during a fire service recall.
+	Arrived                   // finished a one-off trip; see WithTrip.
 )
@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/floorplan"
+	pb "github.com/dshaneg/elevator/internal/rpc/simuvatorv1"
+	"github.com/dshaneg/elevator/internal/session"
+	"github.com/dshaneg/elevator/internal/sim"
+)
+
+func newSimulation(sess *session.Session) *pb.Simulation {
+	state := sess.State()
+	return &pb.Simulation{
+		Id:      state.ID,
+		Name:    state.Name,
+		Now:     timestamppb.New(state.Now),
+		Running: state.Running,
+		Speed:   state.Speed,
+	}
+}
+
+// newState takes a snapshot of the whole Simulation.
+func newState(sess *session.Session) *pb.WatchStateResponse {
+	resp := &pb.WatchStateResponse{Simulation: newSimulation(sess)}
+	sess.Inspect(func(sm *sim.Simulation) {
+		b := sm.Building()
+		for _, z := range b.Zones() {
+			resp.Zones = append(resp.Zones, newZone(b.Plan(), z))
+		}
+		resp.Passengers = int32(len(sm.Passengers()))
+	})
+	return resp
+}
+
+func newZone(plan *floorplan.Plan, z *building.Zone) *pb.Zone {
+	zone := &pb.Zone{
+		Name: z.Name,
+		Mode: z.Bank.Mode().String(),
+	}
+	for _, floor := range z.Floors {
+		zone.Floors = append(zone.Floors, plan.Label(floor))
+	}
+	for _, l := range z.Landings() {
+		zone.Landings = append(zone.Landings, newLanding(plan, z, l.Floor, l.Direction))
+	}
+	for i := range z.Bank.NumCars() {
+		zone.Cars = append(zone.Cars, newCar(plan, z, i))
+	}
+	return zone
+}
+
+func newLanding(plan *floorplan.Plan, z *building.Zone, floor int, direction car.Direction) *pb.Landing {
+	status, c := z.Bank.Status(floor, direction)
+	l := &pb.Landing{
+		Zone:      z.Name,
+		Floor:     plan.Label(floor),
+		Direction: toDirection(direction),
+		Status:    toLandingStatus(status),
+	}
+
+	carIndex := z.Bank.Assigned(floor, direction)
+	if c != nil {
+		carIndex = z.Bank.Index(c)
+	}
+	if carIndex != bank.NoCar {
+		i := int32(carIndex)
+		l.Car = &i
+	}
+	return l
+}
+
+func newCar(plan *floorplan.Plan, z *building.Zone, i int) *pb.Car {
+	c := z.Bank.Car(i)
+	v := &pb.Car{
+		Zone:      z.Name,
+		Index:     int32(i),
+		Floor:     plan.Label(c.Floor()),
+		Direction: toDirection(c.Direction()),
+		Status:    toCarStatus(c.Status()),
+		Door:      toDoor(c.Door()),
+		Mode:      c.Mode().String(),
+		Fault:     c.Fault().String(),
+		Load:      int32(c.Load()),
+		Capacity:  int32(c.Capacity()),
+	}
+	for _, floor := range c.Calls() {
+		v.Calls = append(v.Calls, plan.Label(floor))
+	}
+	return v
+}
+
+func toDirection(d car.Direction) pb.Direction {
+	if d == car.Down {
+		return pb.Direction_DIRECTION_DOWN
+	}
+	return pb.Direction_DIRECTION_UP
+}
+
+func toLandingStatus(s bank.LandingStatus) pb.LandingStatus {
+	switch s {
+	case bank.Idle:
+		return pb.LandingStatus_LANDING_STATUS_IDLE
+	case bank.Waiting:
+		return pb.LandingStatus_LANDING_STATUS_WAITING
+	case bank.Loading:
+		return pb.LandingStatus_LANDING_STATUS_LOADING
+	}
+	return pb.LandingStatus_LANDING_STATUS_UNSPECIFIED
+}
+
+func toCarStatus(s car.Status) pb.CarStatus {
+	switch s {
+	case car.Parked:
+		return pb.CarStatus_CAR_STATUS_PARKED
+	case car.Loading:
+		return pb.CarStatus_CAR_STATUS_LOADING
+	case car.Traveling:
+		return pb.CarStatus_CAR_STATUS_TRAVELING
+	}
+	return pb.CarStatus_CAR_STATUS_UNSPECIFIED
+}
+
+func toDoor(d car.Door) pb.Door {
+	if d == car.Open {
+		return pb.Door_DOOR_OPEN
+	}
+	return pb.Door_DOOR_CLOSED
+}
@@ -0,0 +1,464 @@
+// Package rpc serves simulations of the tower over gRPC, for tooling written in languages that cannot link
+// Go code. The service is defined in proto/simuvator/v1/simulator.proto, from which package simuvatorv1 is
+// generated, and its simulations are kept in a session.Registry, which may be shared with the JSON REST API
+// in package server.
+package rpc
+
+//go:generate sh -c "cd ../.. && buf generate"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/passenger"
+	pb "github.com/dshaneg/elevator/internal/rpc/simuvatorv1"
+	"github.com/dshaneg/elevator/internal/scenario"
+	"github.com/dshaneg/elevator/internal/session"
+	"github.com/dshaneg/elevator/internal/sim"
+	"github.com/dshaneg/elevator/internal/tower"
+)
+
+// Service implements the SimulatorService for the simulations held in a session.Registry.
+type Service struct {
+	pb.UnimplementedSimulatorServiceServer
+
+	sessions *session.Registry
+	logger   *slog.Logger
+}
+
+// Option is a functional option type that allows us to configure the Service.
+type Option func(*Service)
+
+// WithLogger sets the logger that the dispatch decisions and events of the simulations it creates are
+// reported to. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Service) {
+		s.logger = logger
+	}
+}
+
+// New creates a Service for the simulations in the Registry. Register it with a grpc.Server using
+// simuvatorv1.RegisterSimulatorServiceServer.
+func New(sessions *session.Registry, options ...Option) *Service {
+	s := Service{sessions: sessions}
+
+	for _, opt := range options {
+		opt(&s)
+	}
+
+	return &s
+}
+
+func (s *Service) CreateSimulation(ctx context.Context, req *pb.CreateSimulationRequest) (*pb.CreateSimulationResponse, error) {
+	var visitors *scenario.Scenario
+	name := ""
+	if sc := req.GetScenario(); sc != nil {
+		visitors = &scenario.Scenario{Name: sc.GetName(), Traffic: sc.GetTraffic(), Scale: sc.GetScale()}
+		for _, p := range sc.GetProfile() {
+			visitors.Periods = append(visitors.Periods, scenario.Period{
+				Start:      p.GetStart(),
+				Rate:       p.GetRate(),
+				Incoming:   p.GetIncoming(),
+				Outgoing:   p.GetOutgoing(),
+				Interfloor: p.GetInterfloor(),
+			})
+		}
+		for _, e := range sc.GetTimeline() {
+			visitors.Events = append(visitors.Events, scenario.Event{
+				Kind:      e.GetEvent(),
+				At:        e.GetAt(),
+				Until:     e.GetUntil(),
+				Zone:      e.GetZone(),
+				Car:       int(e.GetCar()),
+				Mode:      e.GetMode(),
+				Alternate: e.GetAlternate(),
+			})
+		}
+		name = sc.GetName()
+	}
+
+	built, err := tower.New(tower.Config{
+		Seed:         req.GetSeed(),
+		Visitors:     visitors,
+		Regenerative: req.GetRegenerative(),
+		EnergyWeight: req.GetEnergyWeight(),
+		Logger:       s.logger,
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sess, err := s.sessions.Create(name, built, req.GetSpeed())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.CreateSimulationResponse{Simulation: newSimulation(sess)}, nil
+}
+
+func (s *Service) ListSimulations(ctx context.Context, req *pb.ListSimulationsRequest) (*pb.ListSimulationsResponse, error) {
+	resp := &pb.ListSimulationsResponse{}
+	for _, sess := range s.sessions.List() {
+		resp.Simulations = append(resp.Simulations, newSimulation(sess))
+	}
+	return resp, nil
+}
+
+func (s *Service) GetSimulation(ctx context.Context, req *pb.GetSimulationRequest) (*pb.GetSimulationResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetSimulationResponse{Simulation: newSimulation(sess)}, nil
+}
+
+func (s *Service) DeleteSimulation(ctx context.Context, req *pb.DeleteSimulationRequest) (*pb.DeleteSimulationResponse, error) {
+	if !s.sessions.Delete(req.GetSimulationId()) {
+		return nil, notFound(req.GetSimulationId())
+	}
+	return &pb.DeleteSimulationResponse{}, nil
+}
+
+func (s *Service) Start(ctx context.Context, req *pb.StartRequest) (*pb.StartResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+	sess.Start()
+	return &pb.StartResponse{Simulation: newSimulation(sess)}, nil
+}
+
+func (s *Service) Pause(ctx context.Context, req *pb.PauseRequest) (*pb.PauseResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+	sess.Pause()
+	return &pb.PauseResponse{Simulation: newSimulation(sess)}, nil
+}
+
+func (s *Service) Step(ctx context.Context, req *pb.StepRequest) (*pb.StepResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+
+	steps := int(req.GetSteps())
+	if steps == 0 {
+		steps = 1
+	}
+	if err := sess.Step(steps); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.StepResponse{Simulation: newSimulation(sess)}, nil
+}
+
+func (s *Service) SetSpeed(ctx context.Context, req *pb.SetSpeedRequest) (*pb.SetSpeedResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+	if err := sess.SetSpeed(req.GetSpeed()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.SetSpeedResponse{Simulation: newSimulation(sess)}, nil
+}
+
+func (s *Service) Call(ctx context.Context, req *pb.CallRequest) (*pb.CallResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+	direction, err := fromDirection(req.GetDirection())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.CallResponse{}
+	sess.Do(func(sm *sim.Simulation) {
+		var z *building.Zone
+		var floor int
+		z, floor, err = zoneFloor(sm, req.GetZone(), req.GetFloor())
+		if err != nil {
+			return
+		}
+		var carIndex int
+		if carIndex, err = z.Bank.Call(floor, direction); err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+			return
+		}
+		if carIndex == bank.NoCar {
+			err = status.Errorf(codes.FailedPrecondition, "rpc: no car in zone %q can answer a call at %s", z.Name, req.GetFloor())
+			return
+		}
+		resp.Landing = newLanding(sm.Building().Plan(), z, floor, direction)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) Status(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+	direction, err := fromDirection(req.GetDirection())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.StatusResponse{}
+	sess.Inspect(func(sm *sim.Simulation) {
+		var z *building.Zone
+		var floor int
+		z, floor, err = zoneFloor(sm, req.GetZone(), req.GetFloor())
+		if err != nil {
+			return
+		}
+		resp.Landing = newLanding(sm.Building().Plan(), z, floor, direction)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) Press(ctx context.Context, req *pb.PressRequest) (*pb.PressResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.PressResponse{}
+	sess.Do(func(sm *sim.Simulation) {
+		var z *building.Zone
+		var floor int
+		z, floor, err = zoneFloor(sm, req.GetZone(), req.GetFloor())
+		if err != nil {
+			return
+		}
+		i := int(req.GetCar())
+		if i < 0 || i >= z.Bank.NumCars() {
+			err = status.Errorf(codes.NotFound, "rpc: no car %d in zone %q", i, z.Name)
+			return
+		}
+		if _, err = z.Bank.Car(i).Press(floor); err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+			return
+		}
+		resp.Car = newCar(sm.Building().Plan(), z, i)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetZone(ctx context.Context, req *pb.GetZoneRequest) (*pb.GetZoneResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetZoneResponse{}
+	sess.Inspect(func(sm *sim.Simulation) {
+		z, ok := sm.Building().Zone(req.GetZone())
+		if !ok {
+			err = status.Errorf(codes.NotFound, "rpc: no zone %q", req.GetZone())
+			return
+		}
+		resp.Zone = newZone(sm.Building().Plan(), z)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) FireRecall(ctx context.Context, req *pb.FireRecallRequest) (*pb.FireRecallResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.FireRecallResponse{}
+	sess.Do(func(sm *sim.Simulation) {
+		z, ok := sm.Building().Zone(req.GetZone())
+		if !ok {
+			err = status.Errorf(codes.NotFound, "rpc: no zone %q", req.GetZone())
+			return
+		}
+		z.Bank.Recall(req.GetAlternate())
+		resp.Zone = newZone(sm.Building().Plan(), z)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) FireService(ctx context.Context, req *pb.FireServiceRequest) (*pb.FireServiceResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.FireServiceResponse{}
+	sess.Do(func(sm *sim.Simulation) {
+		z, ok := sm.Building().Zone(req.GetZone())
+		if !ok {
+			err = status.Errorf(codes.NotFound, "rpc: no zone %q", req.GetZone())
+			return
+		}
+		i := int(req.GetCar())
+		if i < 0 || i >= z.Bank.NumCars() {
+			err = status.Errorf(codes.NotFound, "rpc: no car %d in zone %q", i, z.Name)
+			return
+		}
+		if err = z.Bank.FireService(i); err != nil {
+			err = status.Error(codes.FailedPrecondition, err.Error())
+			return
+		}
+		resp.Car = newCar(sm.Building().Plan(), z, i)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) FireRestore(ctx context.Context, req *pb.FireRestoreRequest) (*pb.FireRestoreResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.FireRestoreResponse{}
+	sess.Do(func(sm *sim.Simulation) {
+		z, ok := sm.Building().Zone(req.GetZone())
+		if !ok {
+			err = status.Errorf(codes.NotFound, "rpc: no zone %q", req.GetZone())
+			return
+		}
+		z.Bank.Restore()
+		resp.Zone = newZone(sm.Building().Plan(), z)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AddPassenger brings in a Passenger who waits as long as it takes and never takes the stairs, so that
+// the trip is certain to be made by elevator.
+func (s *Service) AddPassenger(ctx context.Context, req *pb.AddPassengerRequest) (*pb.AddPassengerResponse, error) {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return nil, err
+	}
+
+	sess.Do(func(sm *sim.Simulation) {
+		b := sm.Building()
+		var from, to int
+		from, err = b.Plan().Index(req.GetFrom())
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+			return
+		}
+		to, err = b.Plan().Index(req.GetTo())
+		if err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+			return
+		}
+		if _, err = b.Route(from, to); err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+			return
+		}
+		var p *passenger.Passenger
+		if p, err = passenger.New(b, passenger.WithFloor(from), passenger.WithTrip(to)); err != nil {
+			err = status.Error(codes.InvalidArgument, err.Error())
+			return
+		}
+		sm.AddPassenger(p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AddPassengerResponse{}, nil
+}
+
+func (s *Service) WatchState(req *pb.WatchStateRequest, stream pb.SimulatorService_WatchStateServer) error {
+	sess, err := s.session(req.GetSimulationId())
+	if err != nil {
+		return err
+	}
+
+	changes, stop := sess.Watch()
+	defer stop()
+
+	for {
+		if err := stream.Send(newState(sess)); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		select {
+		case <-changes:
+		case <-sess.Done():
+			return nil
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// session looks up the Session with the given ID.
+func (s *Service) session(id string) (*session.Session, error) {
+	sess, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, notFound(id)
+	}
+	return sess, nil
+}
+
+func notFound(id string) error {
+	return status.Errorf(codes.NotFound, "rpc: no simulation %q", id)
+}
+
+// zoneFloor looks up the named zone and the index of the floor with the given label, which the zone must serve.
+func zoneFloor(sm *sim.Simulation, zone, label string) (*building.Zone, int, error) {
+	z, ok := sm.Building().Zone(zone)
+	if !ok {
+		return nil, 0, status.Errorf(codes.NotFound, "rpc: no zone %q", zone)
+	}
+	floor, err := sm.Building().Plan().Index(label)
+	if err != nil {
+		return nil, 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !z.Serves(floor) {
+		return nil, 0, status.Errorf(codes.InvalidArgument, "rpc: zone %q does not serve floor %s", z.Name, label)
+	}
+	return z, floor, nil
+}
+
+func fromDirection(d pb.Direction) (car.Direction, error) {
+	switch d {
+	case pb.Direction_DIRECTION_UP:
+		return car.Up, nil
+	case pb.Direction_DIRECTION_DOWN:
+		return car.Down, nil
+	default:
+		return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("rpc: direction %v is not up or down", d))
+	}
+}
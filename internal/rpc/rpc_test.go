@@ -0,0 +1,283 @@
+package rpc_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/dshaneg/elevator/internal/rpc"
+	pb "github.com/dshaneg/elevator/internal/rpc/simuvatorv1"
+	"github.com/dshaneg/elevator/internal/session"
+	"github.com/dshaneg/elevator/internal/tower"
+)
+
+// newClient serves the Service on a local socket and returns a client connected to it.
+func newClient(t *testing.T) pb.SimulatorServiceClient {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	sessions := session.NewRegistry()
+	gs := grpc.NewServer()
+	pb.RegisterSimulatorServiceServer(gs, rpc.New(sessions))
+	go gs.Serve(lis)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		gs.Stop()
+		sessions.Close()
+	})
+	return pb.NewSimulatorServiceClient(conn)
+}
+
+func create(t *testing.T, client pb.SimulatorServiceClient) *pb.Simulation {
+	resp, err := client.CreateSimulation(context.Background(), &pb.CreateSimulationRequest{Seed: 7})
+	require.NoError(t, err)
+	return resp.GetSimulation()
+}
+
+func TestSimulationLifecycle(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateSimulation(ctx, &pb.CreateSimulationRequest{
+		Scenario: &pb.Scenario{Name: "quiet day", Traffic: "office", Scale: 0.1},
+	})
+	require.NoError(t, err)
+	sm := created.GetSimulation()
+	assert.Equal(t, "quiet day", sm.GetName())
+	assert.WithinDuration(t, tower.Start, sm.GetNow().AsTime(), 0)
+	assert.Equal(t, float64(session.DefaultSpeed), sm.GetSpeed())
+
+	stepped, err := client.Step(ctx, &pb.StepRequest{SimulationId: sm.GetId(), Steps: 3})
+	require.NoError(t, err)
+	assert.WithinDuration(t, tower.Start.Add(3*time.Minute), stepped.GetSimulation().GetNow().AsTime(), 0)
+
+	speed, err := client.SetSpeed(ctx, &pb.SetSpeedRequest{SimulationId: sm.GetId(), Speed: 60000})
+	require.NoError(t, err)
+	assert.Equal(t, 60000.0, speed.GetSimulation().GetSpeed())
+
+	started, err := client.Start(ctx, &pb.StartRequest{SimulationId: sm.GetId()})
+	require.NoError(t, err)
+	assert.True(t, started.GetSimulation().GetRunning())
+
+	paused, err := client.Pause(ctx, &pb.PauseRequest{SimulationId: sm.GetId()})
+	require.NoError(t, err)
+	assert.False(t, paused.GetSimulation().GetRunning())
+
+	list, err := client.ListSimulations(ctx, &pb.ListSimulationsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetSimulations(), 1)
+
+	_, err = client.DeleteSimulation(ctx, &pb.DeleteSimulationRequest{SimulationId: sm.GetId()})
+	require.NoError(t, err)
+
+	_, err = client.GetSimulation(ctx, &pb.GetSimulationRequest{SimulationId: sm.GetId()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestCallAndStatus(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+	sm := create(t, client)
+
+	called, err := client.Call(ctx, &pb.CallRequest{
+		SimulationId: sm.GetId(), Zone: "low-rise", Floor: "5", Direction: pb.Direction_DIRECTION_DOWN,
+	})
+	require.NoError(t, err)
+	l := called.GetLanding()
+	assert.Equal(t, pb.LandingStatus_LANDING_STATUS_WAITING, l.GetStatus())
+	require.NotNil(t, l.Car)
+
+	for range 8 {
+		_, err = client.Step(ctx, &pb.StepRequest{SimulationId: sm.GetId()})
+		require.NoError(t, err)
+
+		status, err := client.Status(ctx, &pb.StatusRequest{
+			SimulationId: sm.GetId(), Zone: "low-rise", Floor: "5", Direction: pb.Direction_DIRECTION_DOWN,
+		})
+		require.NoError(t, err)
+		if status.GetLanding().GetStatus() == pb.LandingStatus_LANDING_STATUS_LOADING {
+			assert.Equal(t, l.GetCar(), status.GetLanding().GetCar())
+			return
+		}
+	}
+	t.Fatal("no car came to load at 5 going down")
+}
+
+func TestPress(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+	sm := create(t, client)
+
+	pressed, err := client.Press(ctx, &pb.PressRequest{SimulationId: sm.GetId(), Zone: "high-rise", Car: 1, Floor: "15"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"15"}, pressed.GetCar().GetCalls())
+
+	_, err = client.Step(ctx, &pb.StepRequest{SimulationId: sm.GetId()})
+	require.NoError(t, err)
+
+	zone, err := client.GetZone(ctx, &pb.GetZoneRequest{SimulationId: sm.GetId(), Zone: "high-rise"})
+	require.NoError(t, err)
+	c := zone.GetZone().GetCars()[1]
+	assert.Equal(t, pb.CarStatus_CAR_STATUS_TRAVELING, c.GetStatus())
+	assert.Equal(t, pb.Direction_DIRECTION_UP, c.GetDirection())
+	assert.Equal(t, "10", c.GetFloor())
+}
+
+func TestFireRecall(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+	sm := create(t, client)
+
+	recalled, err := client.FireRecall(ctx, &pb.FireRecallRequest{SimulationId: sm.GetId(), Zone: "shuttle", Alternate: true})
+	require.NoError(t, err)
+	assert.Equal(t, "fire recall", recalled.GetZone().GetMode())
+	for _, c := range recalled.GetZone().GetCars() {
+		assert.Equal(t, "fire recall", c.GetMode())
+		assert.Equal(t, pb.Direction_DIRECTION_UP, c.GetDirection(), "cars are recalled to the alternate floor")
+	}
+
+	_, err = client.Call(ctx, &pb.CallRequest{SimulationId: sm.GetId(), Zone: "shuttle", Floor: "G", Direction: pb.Direction_DIRECTION_UP})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "no car answers a hall call during a recall")
+
+	_, err = client.Step(ctx, &pb.StepRequest{SimulationId: sm.GetId(), Steps: 15})
+	require.NoError(t, err)
+
+	serviced, err := client.FireService(ctx, &pb.FireServiceRequest{SimulationId: sm.GetId(), Zone: "shuttle", Car: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "fire service", serviced.GetCar().GetMode())
+	assert.Equal(t, "9", serviced.GetCar().GetFloor())
+
+	restored, err := client.FireRestore(ctx, &pb.FireRestoreRequest{SimulationId: sm.GetId(), Zone: "shuttle"})
+	require.NoError(t, err)
+	assert.Equal(t, "normal", restored.GetZone().GetMode())
+	for _, c := range restored.GetZone().GetCars() {
+		assert.Equal(t, "normal", c.GetMode())
+	}
+}
+
+func TestWatchStateFollowsAnAddedPassenger(t *testing.T) {
+	client := newClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sm := create(t, client)
+
+	stream, err := client.WatchState(ctx, &pb.WatchStateRequest{SimulationId: sm.GetId()})
+	require.NoError(t, err)
+
+	state, err := stream.Recv()
+	require.NoError(t, err)
+	residents := state.GetPassengers()
+	assert.Len(t, state.GetZones(), 3)
+
+	_, err = client.AddPassenger(ctx, &pb.AddPassengerRequest{SimulationId: sm.GetId(), From: "G", To: "4"})
+	require.NoError(t, err)
+
+	state, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, residents+1, state.GetPassengers())
+
+	_, err = client.Step(ctx, &pb.StepRequest{SimulationId: sm.GetId(), Steps: 20})
+	require.NoError(t, err)
+
+	state, err = stream.Recv()
+	require.NoError(t, err)
+	assert.WithinDuration(t, tower.Start.Add(20*time.Minute), state.GetSimulation().GetNow().AsTime(), 0)
+	assert.Equal(t, residents, state.GetPassengers(), "the visitor should have arrived and left")
+
+	_, err = client.DeleteSimulation(ctx, &pb.DeleteSimulationRequest{SimulationId: sm.GetId()})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestErrors(t *testing.T) {
+	client := newClient(t)
+	ctx := context.Background()
+	sm := create(t, client)
+
+	testCases := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"unknown simulation", func() error {
+			_, err := client.Step(ctx, &pb.StepRequest{SimulationId: "99"})
+			return err
+		}, codes.NotFound},
+		{"negative steps", func() error {
+			_, err := client.Step(ctx, &pb.StepRequest{SimulationId: sm.GetId(), Steps: -1})
+			return err
+		}, codes.InvalidArgument},
+		{"too many steps", func() error {
+			_, err := client.Step(ctx, &pb.StepRequest{SimulationId: sm.GetId(), Steps: session.MaxSteps + 1})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown traffic", func() error {
+			_, err := client.CreateSimulation(ctx, &pb.CreateSimulationRequest{Scenario: &pb.Scenario{Traffic: "stadium"}})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown zone in the timeline", func() error {
+			_, err := client.CreateSimulation(ctx, &pb.CreateSimulationRequest{Scenario: &pb.Scenario{
+				Traffic:  "office",
+				Timeline: []*pb.TimelineEvent{{Event: "fire recall", At: "08:00", Zone: "penthouse"}},
+			}})
+			return err
+		}, codes.InvalidArgument},
+		{"no speed", func() error {
+			_, err := client.SetSpeed(ctx, &pb.SetSpeedRequest{SimulationId: sm.GetId()})
+			return err
+		}, codes.InvalidArgument},
+		{"no direction", func() error {
+			_, err := client.Call(ctx, &pb.CallRequest{SimulationId: sm.GetId(), Zone: "low-rise", Floor: "5"})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown zone", func() error {
+			_, err := client.GetZone(ctx, &pb.GetZoneRequest{SimulationId: sm.GetId(), Zone: "penthouse"})
+			return err
+		}, codes.NotFound},
+		{"floor not served", func() error {
+			_, err := client.Call(ctx, &pb.CallRequest{SimulationId: sm.GetId(), Zone: "shuttle", Floor: "5", Direction: pb.Direction_DIRECTION_UP})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown car", func() error {
+			_, err := client.Press(ctx, &pb.PressRequest{SimulationId: sm.GetId(), Zone: "shuttle", Car: 2, Floor: "9"})
+			return err
+		}, codes.NotFound},
+		{"fire recall of an unknown zone", func() error {
+			_, err := client.FireRecall(ctx, &pb.FireRecallRequest{SimulationId: sm.GetId(), Zone: "penthouse"})
+			return err
+		}, codes.NotFound},
+		{"fire service before a recall", func() error {
+			_, err := client.FireService(ctx, &pb.FireServiceRequest{SimulationId: sm.GetId(), Zone: "low-rise"})
+			return err
+		}, codes.FailedPrecondition},
+		{"fire service of an unknown car", func() error {
+			_, err := client.FireService(ctx, &pb.FireServiceRequest{SimulationId: sm.GetId(), Zone: "shuttle", Car: 2})
+			return err
+		}, codes.NotFound},
+		{"unknown floor", func() error {
+			_, err := client.AddPassenger(ctx, &pb.AddPassengerRequest{SimulationId: sm.GetId(), From: "G", To: "13"})
+			return err
+		}, codes.InvalidArgument},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.code, status.Code(tc.call()))
+		})
+	}
+}
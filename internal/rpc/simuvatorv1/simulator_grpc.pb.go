@@ -0,0 +1,775 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: simuvator/v1/simulator.proto
+
+// The simuvator elevator simulator, for tooling written in other languages.
+//
+// Simulations are of the office tower that cmd/simuvator runs, and are shared with the JSON REST API
+// when both are served by `simuvator serve`. Floors are given and returned by their labels, such as
+// "B1", "G" or "12".
+
+package simuvatorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SimulatorService_CreateSimulation_FullMethodName = "/simuvator.v1.SimulatorService/CreateSimulation"
+	SimulatorService_ListSimulations_FullMethodName  = "/simuvator.v1.SimulatorService/ListSimulations"
+	SimulatorService_GetSimulation_FullMethodName    = "/simuvator.v1.SimulatorService/GetSimulation"
+	SimulatorService_DeleteSimulation_FullMethodName = "/simuvator.v1.SimulatorService/DeleteSimulation"
+	SimulatorService_Start_FullMethodName            = "/simuvator.v1.SimulatorService/Start"
+	SimulatorService_Pause_FullMethodName            = "/simuvator.v1.SimulatorService/Pause"
+	SimulatorService_Step_FullMethodName             = "/simuvator.v1.SimulatorService/Step"
+	SimulatorService_SetSpeed_FullMethodName         = "/simuvator.v1.SimulatorService/SetSpeed"
+	SimulatorService_Call_FullMethodName             = "/simuvator.v1.SimulatorService/Call"
+	SimulatorService_Status_FullMethodName           = "/simuvator.v1.SimulatorService/Status"
+	SimulatorService_Press_FullMethodName            = "/simuvator.v1.SimulatorService/Press"
+	SimulatorService_GetZone_FullMethodName          = "/simuvator.v1.SimulatorService/GetZone"
+	SimulatorService_FireRecall_FullMethodName       = "/simuvator.v1.SimulatorService/FireRecall"
+	SimulatorService_FireService_FullMethodName      = "/simuvator.v1.SimulatorService/FireService"
+	SimulatorService_FireRestore_FullMethodName      = "/simuvator.v1.SimulatorService/FireRestore"
+	SimulatorService_AddPassenger_FullMethodName     = "/simuvator.v1.SimulatorService/AddPassenger"
+	SimulatorService_WatchState_FullMethodName       = "/simuvator.v1.SimulatorService/WatchState"
+)
+
+// SimulatorServiceClient is the client API for SimulatorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SimulatorServiceClient interface {
+	// CreateSimulation creates a paused simulation of the tower.
+	CreateSimulation(ctx context.Context, in *CreateSimulationRequest, opts ...grpc.CallOption) (*CreateSimulationResponse, error)
+	ListSimulations(ctx context.Context, in *ListSimulationsRequest, opts ...grpc.CallOption) (*ListSimulationsResponse, error)
+	GetSimulation(ctx context.Context, in *GetSimulationRequest, opts ...grpc.CallOption) (*GetSimulationResponse, error)
+	// DeleteSimulation tears a simulation down, ending any streams watching it.
+	DeleteSimulation(ctx context.Context, in *DeleteSimulationRequest, opts ...grpc.CallOption) (*DeleteSimulationResponse, error)
+	// Start steps the simulation in real time, scaled by its speed, until it is paused.
+	Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*StartResponse, error)
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error)
+	// Step steps the simulation straight away, whether or not it is running.
+	Step(ctx context.Context, in *StepRequest, opts ...grpc.CallOption) (*StepResponse, error)
+	// SetSpeed sets how many times faster than real time the simulation runs.
+	SetSpeed(ctx context.Context, in *SetSpeedRequest, opts ...grpc.CallOption) (*SetSpeedResponse, error)
+	// Call presses a hall call button, as a passenger at the landing does. It fails with FAILED_PRECONDITION
+	// if no car can answer the call, as during a fire recall.
+	Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error)
+	// Status returns the status of a landing.
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Press presses a car's button for a floor, as a passenger aboard does.
+	Press(ctx context.Context, in *PressRequest, opts ...grpc.CallOption) (*PressResponse, error)
+	// GetZone returns a zone with its landings and cars.
+	GetZone(ctx context.Context, in *GetZoneRequest, opts ...grpc.CallOption) (*GetZoneResponse, error)
+	// FireRecall starts Phase I emergency recall of a zone: its hall calls are cancelled and every car
+	// returns non-stop to the recall floor.
+	FireRecall(ctx context.Context, in *FireRecallRequest, opts ...grpc.CallOption) (*FireRecallResponse, error)
+	// FireService hands a recalled car over to a firefighter. The zone must already be in recall.
+	FireService(ctx context.Context, in *FireServiceRequest, opts ...grpc.CallOption) (*FireServiceResponse, error)
+	// FireRestore returns a zone and its cars to normal operation.
+	FireRestore(ctx context.Context, in *FireRestoreRequest, opts ...grpc.CallOption) (*FireRestoreResponse, error)
+	// AddPassenger brings a passenger into the simulation who makes a single trip and then leaves.
+	AddPassenger(ctx context.Context, in *AddPassengerRequest, opts ...grpc.CallOption) (*AddPassengerResponse, error)
+	// WatchState sends the state of the simulation straight away and again whenever it changes, until
+	// the simulation is deleted or the client goes away. A client that falls behind is sent the latest
+	// state rather than every one in between.
+	WatchState(ctx context.Context, in *WatchStateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchStateResponse], error)
+}
+
+type simulatorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimulatorServiceClient(cc grpc.ClientConnInterface) SimulatorServiceClient {
+	return &simulatorServiceClient{cc}
+}
+
+func (c *simulatorServiceClient) CreateSimulation(ctx context.Context, in *CreateSimulationRequest, opts ...grpc.CallOption) (*CreateSimulationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSimulationResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_CreateSimulation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) ListSimulations(ctx context.Context, in *ListSimulationsRequest, opts ...grpc.CallOption) (*ListSimulationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSimulationsResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_ListSimulations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) GetSimulation(ctx context.Context, in *GetSimulationRequest, opts ...grpc.CallOption) (*GetSimulationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSimulationResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_GetSimulation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) DeleteSimulation(ctx context.Context, in *DeleteSimulationRequest, opts ...grpc.CallOption) (*DeleteSimulationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSimulationResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_DeleteSimulation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*StartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_Start_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_Pause_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) Step(ctx context.Context, in *StepRequest, opts ...grpc.CallOption) (*StepResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StepResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_Step_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) SetSpeed(ctx context.Context, in *SetSpeedRequest, opts ...grpc.CallOption) (*SetSpeedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetSpeedResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_SetSpeed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CallResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_Call_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) Press(ctx context.Context, in *PressRequest, opts ...grpc.CallOption) (*PressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PressResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_Press_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) GetZone(ctx context.Context, in *GetZoneRequest, opts ...grpc.CallOption) (*GetZoneResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetZoneResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_GetZone_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) FireRecall(ctx context.Context, in *FireRecallRequest, opts ...grpc.CallOption) (*FireRecallResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FireRecallResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_FireRecall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) FireService(ctx context.Context, in *FireServiceRequest, opts ...grpc.CallOption) (*FireServiceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FireServiceResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_FireService_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) FireRestore(ctx context.Context, in *FireRestoreRequest, opts ...grpc.CallOption) (*FireRestoreResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FireRestoreResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_FireRestore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) AddPassenger(ctx context.Context, in *AddPassengerRequest, opts ...grpc.CallOption) (*AddPassengerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddPassengerResponse)
+	err := c.cc.Invoke(ctx, SimulatorService_AddPassenger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulatorServiceClient) WatchState(ctx context.Context, in *WatchStateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchStateResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SimulatorService_ServiceDesc.Streams[0], SimulatorService_WatchState_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchStateRequest, WatchStateResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SimulatorService_WatchStateClient = grpc.ServerStreamingClient[WatchStateResponse]
+
+// SimulatorServiceServer is the server API for SimulatorService service.
+// All implementations must embed UnimplementedSimulatorServiceServer
+// for forward compatibility.
+type SimulatorServiceServer interface {
+	// CreateSimulation creates a paused simulation of the tower.
+	CreateSimulation(context.Context, *CreateSimulationRequest) (*CreateSimulationResponse, error)
+	ListSimulations(context.Context, *ListSimulationsRequest) (*ListSimulationsResponse, error)
+	GetSimulation(context.Context, *GetSimulationRequest) (*GetSimulationResponse, error)
+	// DeleteSimulation tears a simulation down, ending any streams watching it.
+	DeleteSimulation(context.Context, *DeleteSimulationRequest) (*DeleteSimulationResponse, error)
+	// Start steps the simulation in real time, scaled by its speed, until it is paused.
+	Start(context.Context, *StartRequest) (*StartResponse, error)
+	Pause(context.Context, *PauseRequest) (*PauseResponse, error)
+	// Step steps the simulation straight away, whether or not it is running.
+	Step(context.Context, *StepRequest) (*StepResponse, error)
+	// SetSpeed sets how many times faster than real time the simulation runs.
+	SetSpeed(context.Context, *SetSpeedRequest) (*SetSpeedResponse, error)
+	// Call presses a hall call button, as a passenger at the landing does. It fails with FAILED_PRECONDITION
+	// if no car can answer the call, as during a fire recall.
+	Call(context.Context, *CallRequest) (*CallResponse, error)
+	// Status returns the status of a landing.
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	// Press presses a car's button for a floor, as a passenger aboard does.
+	Press(context.Context, *PressRequest) (*PressResponse, error)
+	// GetZone returns a zone with its landings and cars.
+	GetZone(context.Context, *GetZoneRequest) (*GetZoneResponse, error)
+	// FireRecall starts Phase I emergency recall of a zone: its hall calls are cancelled and every car
+	// returns non-stop to the recall floor.
+	FireRecall(context.Context, *FireRecallRequest) (*FireRecallResponse, error)
+	// FireService hands a recalled car over to a firefighter. The zone must already be in recall.
+	FireService(context.Context, *FireServiceRequest) (*FireServiceResponse, error)
+	// FireRestore returns a zone and its cars to normal operation.
+	FireRestore(context.Context, *FireRestoreRequest) (*FireRestoreResponse, error)
+	// AddPassenger brings a passenger into the simulation who makes a single trip and then leaves.
+	AddPassenger(context.Context, *AddPassengerRequest) (*AddPassengerResponse, error)
+	// WatchState sends the state of the simulation straight away and again whenever it changes, until
+	// the simulation is deleted or the client goes away. A client that falls behind is sent the latest
+	// state rather than every one in between.
+	WatchState(*WatchStateRequest, grpc.ServerStreamingServer[WatchStateResponse]) error
+	mustEmbedUnimplementedSimulatorServiceServer()
+}
+
+// UnimplementedSimulatorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSimulatorServiceServer struct{}
+
+func (UnimplementedSimulatorServiceServer) CreateSimulation(context.Context, *CreateSimulationRequest) (*CreateSimulationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSimulation not implemented")
+}
+func (UnimplementedSimulatorServiceServer) ListSimulations(context.Context, *ListSimulationsRequest) (*ListSimulationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSimulations not implemented")
+}
+func (UnimplementedSimulatorServiceServer) GetSimulation(context.Context, *GetSimulationRequest) (*GetSimulationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSimulation not implemented")
+}
+func (UnimplementedSimulatorServiceServer) DeleteSimulation(context.Context, *DeleteSimulationRequest) (*DeleteSimulationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSimulation not implemented")
+}
+func (UnimplementedSimulatorServiceServer) Start(context.Context, *StartRequest) (*StartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedSimulatorServiceServer) Pause(context.Context, *PauseRequest) (*PauseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedSimulatorServiceServer) Step(context.Context, *StepRequest) (*StepResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Step not implemented")
+}
+func (UnimplementedSimulatorServiceServer) SetSpeed(context.Context, *SetSpeedRequest) (*SetSpeedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSpeed not implemented")
+}
+func (UnimplementedSimulatorServiceServer) Call(context.Context, *CallRequest) (*CallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Call not implemented")
+}
+func (UnimplementedSimulatorServiceServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedSimulatorServiceServer) Press(context.Context, *PressRequest) (*PressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Press not implemented")
+}
+func (UnimplementedSimulatorServiceServer) GetZone(context.Context, *GetZoneRequest) (*GetZoneResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetZone not implemented")
+}
+func (UnimplementedSimulatorServiceServer) FireRecall(context.Context, *FireRecallRequest) (*FireRecallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FireRecall not implemented")
+}
+func (UnimplementedSimulatorServiceServer) FireService(context.Context, *FireServiceRequest) (*FireServiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FireService not implemented")
+}
+func (UnimplementedSimulatorServiceServer) FireRestore(context.Context, *FireRestoreRequest) (*FireRestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FireRestore not implemented")
+}
+func (UnimplementedSimulatorServiceServer) AddPassenger(context.Context, *AddPassengerRequest) (*AddPassengerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPassenger not implemented")
+}
+func (UnimplementedSimulatorServiceServer) WatchState(*WatchStateRequest, grpc.ServerStreamingServer[WatchStateResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchState not implemented")
+}
+func (UnimplementedSimulatorServiceServer) mustEmbedUnimplementedSimulatorServiceServer() {}
+func (UnimplementedSimulatorServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeSimulatorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimulatorServiceServer will
+// result in compilation errors.
+type UnsafeSimulatorServiceServer interface {
+	mustEmbedUnimplementedSimulatorServiceServer()
+}
+
+func RegisterSimulatorServiceServer(s grpc.ServiceRegistrar, srv SimulatorServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSimulatorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SimulatorService_ServiceDesc, srv)
+}
+
+func _SimulatorService_CreateSimulation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSimulationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).CreateSimulation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_CreateSimulation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).CreateSimulation(ctx, req.(*CreateSimulationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_ListSimulations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSimulationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).ListSimulations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_ListSimulations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).ListSimulations(ctx, req.(*ListSimulationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_GetSimulation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSimulationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).GetSimulation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_GetSimulation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).GetSimulation(ctx, req.(*GetSimulationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_DeleteSimulation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSimulationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).DeleteSimulation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_DeleteSimulation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).DeleteSimulation(ctx, req.(*DeleteSimulationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_Start_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).Start(ctx, req.(*StartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_Step_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StepRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).Step(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_Step_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).Step(ctx, req.(*StepRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_SetSpeed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSpeedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).SetSpeed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_SetSpeed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).SetSpeed(ctx, req.(*SetSpeedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_Call_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).Call(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_Press_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).Press(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_Press_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).Press(ctx, req.(*PressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_GetZone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetZoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).GetZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_GetZone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).GetZone(ctx, req.(*GetZoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_FireRecall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FireRecallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).FireRecall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_FireRecall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).FireRecall(ctx, req.(*FireRecallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_FireService_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FireServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).FireService(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_FireService_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).FireService(ctx, req.(*FireServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_FireRestore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FireRestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).FireRestore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_FireRestore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).FireRestore(ctx, req.(*FireRestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_AddPassenger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddPassengerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulatorServiceServer).AddPassenger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimulatorService_AddPassenger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulatorServiceServer).AddPassenger(ctx, req.(*AddPassengerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimulatorService_WatchState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SimulatorServiceServer).WatchState(m, &grpc.GenericServerStream[WatchStateRequest, WatchStateResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SimulatorService_WatchStateServer = grpc.ServerStreamingServer[WatchStateResponse]
+
+// SimulatorService_ServiceDesc is the grpc.ServiceDesc for SimulatorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SimulatorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "simuvator.v1.SimulatorService",
+	HandlerType: (*SimulatorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateSimulation",
+			Handler:    _SimulatorService_CreateSimulation_Handler,
+		},
+		{
+			MethodName: "ListSimulations",
+			Handler:    _SimulatorService_ListSimulations_Handler,
+		},
+		{
+			MethodName: "GetSimulation",
+			Handler:    _SimulatorService_GetSimulation_Handler,
+		},
+		{
+			MethodName: "DeleteSimulation",
+			Handler:    _SimulatorService_DeleteSimulation_Handler,
+		},
+		{
+			MethodName: "Start",
+			Handler:    _SimulatorService_Start_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _SimulatorService_Pause_Handler,
+		},
+		{
+			MethodName: "Step",
+			Handler:    _SimulatorService_Step_Handler,
+		},
+		{
+			MethodName: "SetSpeed",
+			Handler:    _SimulatorService_SetSpeed_Handler,
+		},
+		{
+			MethodName: "Call",
+			Handler:    _SimulatorService_Call_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _SimulatorService_Status_Handler,
+		},
+		{
+			MethodName: "Press",
+			Handler:    _SimulatorService_Press_Handler,
+		},
+		{
+			MethodName: "GetZone",
+			Handler:    _SimulatorService_GetZone_Handler,
+		},
+		{
+			MethodName: "FireRecall",
+			Handler:    _SimulatorService_FireRecall_Handler,
+		},
+		{
+			MethodName: "FireService",
+			Handler:    _SimulatorService_FireService_Handler,
+		},
+		{
+			MethodName: "FireRestore",
+			Handler:    _SimulatorService_FireRestore_Handler,
+		},
+		{
+			MethodName: "AddPassenger",
+			Handler:    _SimulatorService_AddPassenger_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchState",
+			Handler:       _SimulatorService_WatchState_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "simuvator/v1/simulator.proto",
+}
@@ -0,0 +1,226 @@
+// Package scenario reads the description of a Simulation's traffic from a JSON file, so that planners
+// can try out different days without changing code.
+//
+// A scenario names one of the built-in traffic templates, or lays out its own arrival profile:
+//
+//	{
+//		"name": "conference week",
+//		"traffic": "hotel",
+//		"scale": 1.5
+//	}
+//
+//	{
+//		"name": "night shift",
+//		"profile": [
+//			{"start": "00:00", "rate": 5, "interfloor": 1},
+//			{"start": "22:00", "rate": 60, "incoming": 9, "outgoing": 1}
+//		]
+//	}
+//
+// A scenario may also lay out a timeline of what happens to the tower's cars over the day: maintenance
+// windows, changes of operating mode, and fire recall, fire service and restore.
+//
+//	{
+//		"name": "a car down for the morning rush",
+//		"traffic": "office",
+//		"timeline": [
+//			{"event": "maintenance", "at": "08:00", "until": "09:30", "zone": "low-rise", "car": 1},
+//			{"event": "mode", "at": "11:00", "zone": "high-rise", "car": 0, "mode": "independent service"},
+//			{"event": "fire recall", "at": "14:00", "zone": "low-rise", "alternate": true},
+//			{"event": "fire service", "at": "14:05", "zone": "low-rise", "car": 2},
+//			{"event": "fire restore", "at": "14:30", "zone": "low-rise"}
+//		]
+//	}
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/sim"
+)
+
+// Scenario describes the traffic through a Building over a day, and what happens to its cars.
+type Scenario struct {
+	Name    string   `json:"name"`
+	Traffic string   `json:"traffic"`  // the name of a built-in traffic template; see arrivals.TemplateNames
+	Scale   float64  `json:"scale"`    // multiplies the arrival rates; 0 is taken as 1
+	Periods []Period `json:"profile"`  // a custom arrival profile, used instead of a template
+	Events  []Event  `json:"timeline"` // what happens to the cars over the day, in any order
+}
+
+// Period is an arrivals.Period as written in a scenario file, with its start as a time of day such as "07:30".
+type Period struct {
+	Start      string  `json:"start"`
+	Rate       float64 `json:"rate"`
+	Incoming   float64 `json:"incoming"`
+	Outgoing   float64 `json:"outgoing"`
+	Interfloor float64 `json:"interfloor"`
+}
+
+// Event is something scheduled to happen to a zone or one of its cars, as written in a scenario file,
+// with its times as times of day such as "07:30".
+type Event struct {
+	Kind      string `json:"event"` // one of EventKinds
+	At        string `json:"at"`
+	Until     string `json:"until"` // when a maintenance window ends
+	Zone      string `json:"zone"`
+	Car       int    `json:"car"`       // the index of the car in its zone, for maintenance, mode and fire service
+	Mode      string `json:"mode"`      // the operating mode a car is switched to, such as "independent service"
+	Alternate bool   `json:"alternate"` // recall to the alternate floor, as when the fire is on the designated one
+}
+
+// EventKinds are the kinds of Event that a timeline may hold.
+var EventKinds = []string{"maintenance", "mode", "fire recall", "fire service", "fire restore"}
+
+// modes are the operating modes that a "mode" Event may switch a car to. The fire modes are entered
+// with the fire events instead.
+var modes = []car.Mode{car.Normal, car.OutOfService, car.Inspection, car.Independent, car.Attendant}
+
+// Load reads a Scenario from JSON.
+func Load(r io.Reader) (Scenario, error) {
+	var s Scenario
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return Scenario{}, fmt.Errorf("scenario: %w", err)
+	}
+	return s, nil
+}
+
+// LoadFile reads a Scenario from the JSON file at the given path.
+func LoadFile(path string) (Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("scenario: %w", err)
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// HasTraffic reports whether the Scenario names a traffic template or lays out a profile, rather than only
+// a timeline.
+func (s Scenario) HasTraffic() bool {
+	return s.Traffic != "" || len(s.Periods) > 0
+}
+
+// Profile returns the arrival profile of the Scenario: its own, or else the template it names, scaled.
+func (s Scenario) Profile() (arrivals.Profile, error) {
+	var profile arrivals.Profile
+	switch {
+	case len(s.Periods) > 0 && s.Traffic != "":
+		return nil, errors.New("scenario: give either a traffic template or a profile, not both")
+	case len(s.Periods) > 0:
+		for _, p := range s.Periods {
+			start, err := timeOfDay(p.Start)
+			if err != nil {
+				return nil, fmt.Errorf("scenario: period start %w", err)
+			}
+			profile = append(profile, arrivals.Period{
+				Start:      start,
+				Rate:       p.Rate,
+				Incoming:   p.Incoming,
+				Outgoing:   p.Outgoing,
+				Interfloor: p.Interfloor,
+			})
+		}
+	case s.Traffic != "":
+		template, err := arrivals.Template(s.Traffic)
+		if err != nil {
+			return nil, err
+		}
+		profile = template
+	default:
+		return nil, errors.New("scenario: no traffic template or profile")
+	}
+
+	if s.Scale != 0 {
+		profile = profile.Scale(s.Scale)
+	}
+	return profile, nil
+}
+
+// Timeline returns the Events of the Scenario as sim.Events on the day that starts at start, checking that
+// the zones and cars they name are in the Building. Events must not be scheduled before start.
+func (s Scenario) Timeline(b *building.Building, start time.Time) ([]sim.Event, error) {
+	midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	at := func(label string) (time.Time, error) {
+		d, err := timeOfDay(label)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t := midnight.Add(d)
+		if t.Before(start) {
+			return time.Time{}, fmt.Errorf("%s is before the day starts at %s", label, start.Format("15:04"))
+		}
+		return t, nil
+	}
+
+	var events []sim.Event
+	for i, e := range s.Events {
+		from, err := at(e.At)
+		if err != nil {
+			return nil, fmt.Errorf("scenario: timeline event %d: %w", i, err)
+		}
+		z, ok := b.Zone(e.Zone)
+		if !ok {
+			return nil, fmt.Errorf("scenario: timeline event %d: no zone %q", i, e.Zone)
+		}
+		if e.Car < 0 || e.Car >= z.Bank.NumCars() {
+			return nil, fmt.Errorf("scenario: timeline event %d: no car %d in zone %q", i, e.Car, e.Zone)
+		}
+
+		switch e.Kind {
+		case "maintenance":
+			to, err := at(e.Until)
+			if err != nil {
+				return nil, fmt.Errorf("scenario: timeline event %d: until %w", i, err)
+			}
+			if !to.After(from) {
+				return nil, fmt.Errorf("scenario: timeline event %d: maintenance until %s ends before it starts at %s", i, e.Until, e.At)
+			}
+			events = append(events, sim.MaintenanceWindow(from, to, e.Zone, e.Car)...)
+		case "mode":
+			mode, err := parseMode(e.Mode)
+			if err != nil {
+				return nil, fmt.Errorf("scenario: timeline event %d: %w", i, err)
+			}
+			events = append(events, sim.CarMode(from, e.Zone, e.Car, mode))
+		case "fire recall":
+			events = append(events, sim.FireRecall(from, e.Zone, e.Alternate))
+		case "fire service":
+			events = append(events, sim.FireService(from, e.Zone, e.Car))
+		case "fire restore":
+			events = append(events, sim.FireRestore(from, e.Zone))
+		default:
+			return nil, fmt.Errorf("scenario: timeline event %d: %q is not one of %q", i, e.Kind, EventKinds)
+		}
+	}
+	return events, nil
+}
+
+// timeOfDay parses a time of day such as "07:30" as the time since midnight.
+func timeOfDay(label string) (time.Duration, error) {
+	t, err := time.Parse("15:04", label)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day like 07:30", label)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseMode(name string) (car.Mode, error) {
+	for _, m := range modes {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("mode %q is not one of %q", name, modes)
+}
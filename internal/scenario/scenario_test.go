@@ -0,0 +1,168 @@
+package scenario_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/scenario"
+	"github.com/dshaneg/elevator/internal/tower"
+)
+
+func TestProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected arrivals.Profile
+	}{
+		{
+			name:     "A template",
+			json:     `{"name": "hotel", "traffic": "hotel"}`,
+			expected: arrivals.Hotel,
+		},
+		{
+			name:     "A scaled template",
+			json:     `{"traffic": "residential", "scale": 2}`,
+			expected: arrivals.Residential.Scale(2),
+		},
+		{
+			name: "A custom profile",
+			json: `{"profile": [
+				{"start": "00:00", "rate": 5, "interfloor": 1},
+				{"start": "22:30", "rate": 60, "incoming": 9, "outgoing": 1}
+			]}`,
+			expected: arrivals.Profile{
+				{Start: 0, Rate: 5, Interfloor: 1},
+				{Start: 22*time.Hour + 30*time.Minute, Rate: 60, Incoming: 9, Outgoing: 1},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := scenario.Load(strings.NewReader(tc.json))
+			assert.NoError(t, err)
+
+			profile, err := s.Profile()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, profile)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, err := scenario.Load(strings.NewReader(`{"trafic": "hotel"}`))
+	assert.Error(t, err, "misspelt fields are reported")
+
+	_, err = scenario.LoadFile("no-such-file.json")
+	assert.Error(t, err)
+
+	for _, json := range []string{
+		`{}`,
+		`{"traffic": "stadium"}`,
+		`{"traffic": "hotel", "profile": [{"start": "00:00", "rate": 1}]}`,
+		`{"profile": [{"start": "7am", "rate": 1}]}`,
+	} {
+		s, err := scenario.Load(strings.NewReader(json))
+		assert.NoError(t, err)
+		_, err = s.Profile()
+		assert.Error(t, err, json)
+	}
+}
+
+// newBuilding returns the tower's Building, whose zones the timelines name.
+func newBuilding(t *testing.T) *building.Building {
+	s, err := tower.New(tower.Config{})
+	require.NoError(t, err)
+	return s.Building()
+}
+
+func TestTimeline(t *testing.T) {
+	s, err := scenario.Load(strings.NewReader(`{"traffic": "office", "timeline": [
+		{"event": "maintenance", "at": "08:00", "until": "09:30", "zone": "high-rise", "car": 1},
+		{"event": "mode", "at": "11:00", "zone": "shuttle", "car": 1, "mode": "independent service"},
+		{"event": "fire recall", "at": "14:00", "zone": "low-rise", "alternate": true},
+		{"event": "fire service", "at": "14:05", "zone": "low-rise", "car": 2},
+		{"event": "fire restore", "at": "14:30", "zone": "low-rise"}
+	]}`))
+	require.NoError(t, err)
+
+	b := newBuilding(t)
+	events, err := s.Timeline(b, tower.Start)
+	require.NoError(t, err)
+
+	day := time.Date(tower.Start.Year(), tower.Start.Month(), tower.Start.Day(), 0, 0, 0, 0, tower.Start.Location())
+	expected := []struct {
+		at   time.Duration
+		name string
+	}{
+		{8 * time.Hour, "high-rise car 1 out of service"},
+		{9*time.Hour + 30*time.Minute, "high-rise car 1 normal"},
+		{11 * time.Hour, "shuttle car 1 independent service"},
+		{14 * time.Hour, "fire recall low-rise"},
+		{14*time.Hour + 5*time.Minute, "fire service low-rise car 2"},
+		{14*time.Hour + 30*time.Minute, "fire restore low-rise"},
+	}
+	require.Len(t, events, len(expected))
+	for i, e := range expected {
+		assert.Equal(t, day.Add(e.at), events[i].At, e.name)
+		assert.Equal(t, e.name, events[i].Name)
+	}
+
+	highRise, _ := b.Zone("high-rise")
+	shuttle, _ := b.Zone("shuttle")
+	lowRise, _ := b.Zone("low-rise")
+	modes := []func() any{
+		func() any { return highRise.Bank.Car(1).Mode() },
+		func() any { return highRise.Bank.Car(1).Mode() },
+		func() any { return shuttle.Bank.Car(1).Mode() },
+		func() any { return lowRise.Bank.Mode() },
+		func() any { return lowRise.Bank.Car(2).Mode() },
+		func() any { return lowRise.Bank.Mode() },
+	}
+	for i, want := range []any{car.OutOfService, car.Normal, car.Independent, bank.FireRecall, car.FireService, bank.Normal} {
+		require.NoError(t, events[i].Action(b), events[i].Name)
+		assert.Equal(t, want, modes[i](), events[i].Name)
+	}
+}
+
+func TestTimelineErrors(t *testing.T) {
+	b := newBuilding(t)
+
+	for _, json := range []string{
+		`{"timeline": [{"event": "earthquake", "at": "08:00", "zone": "low-rise"}]}`,
+		`{"timeline": [{"event": "fire recall", "at": "8am", "zone": "low-rise"}]}`,
+		`{"timeline": [{"event": "fire recall", "at": "06:00", "zone": "low-rise"}]}`,
+		`{"timeline": [{"event": "fire recall", "at": "08:00", "zone": "penthouse"}]}`,
+		`{"timeline": [{"event": "fire service", "at": "08:00", "zone": "shuttle", "car": 2}]}`,
+		`{"timeline": [{"event": "fire service", "at": "08:00", "zone": "shuttle", "car": -1}]}`,
+		`{"timeline": [{"event": "maintenance", "at": "08:00", "zone": "low-rise"}]}`,
+		`{"timeline": [{"event": "maintenance", "at": "08:00", "until": "07:30", "zone": "low-rise"}]}`,
+		`{"timeline": [{"event": "mode", "at": "08:00", "zone": "low-rise", "mode": "fire service"}]}`,
+		`{"timeline": [{"event": "mode", "at": "08:00", "zone": "low-rise", "mode": "party"}]}`,
+	} {
+		s, err := scenario.Load(strings.NewReader(json))
+		require.NoError(t, err)
+		_, err = s.Timeline(b, tower.Start)
+		assert.Error(t, err, json)
+	}
+}
+
+func TestExampleScenarios(t *testing.T) {
+	b := newBuilding(t)
+	for _, path := range []string{"../../scenarios/hotel.json", "../../scenarios/night-shift.json", "../../scenarios/rush-hour-maintenance.json"} {
+		s, err := scenario.LoadFile(path)
+		assert.NoError(t, err)
+		_, err = s.Profile()
+		assert.NoError(t, err, path)
+		_, err = s.Timeline(b, tower.Start)
+		assert.NoError(t, err, path)
+	}
+}
@@ -0,0 +1,266 @@
+// Package server exposes simulations of the tower over a JSON REST API, so that integration tests and
+// UI prototypes can drive the simulator without linking Go code. The simulations are kept in a
+// session.Registry, which may be shared with the gRPC service in package rpc.
+//
+//	POST   /simulations                          create a simulation from a scenario
+//	GET    /simulations                          list the simulations
+//	GET    /simulations/{id}                     the clock and run state of a simulation
+//	DELETE /simulations/{id}                     tear a simulation down
+//	POST   /simulations/{id}/start               step in real time, scaled by the speed
+//	POST   /simulations/{id}/pause               stop stepping
+//	POST   /simulations/{id}/step                step a number of times straight away
+//	PUT    /simulations/{id}/speed               set how many times faster than real time to run
+//	GET    /simulations/{id}/metrics             Prometheus metrics, in the text format
+//	GET    /simulations/{id}/zones               every zone, with its landings and cars
+//	GET    /simulations/{id}/zones/{zone}        one zone
+//	GET    /simulations/{id}/zones/{zone}/landings
+//	POST   /simulations/{id}/zones/{zone}/hall-calls
+//	POST   /simulations/{id}/zones/{zone}/fire-recall
+//	POST   /simulations/{id}/zones/{zone}/fire-restore
+//	GET    /simulations/{id}/zones/{zone}/cars
+//	GET    /simulations/{id}/zones/{zone}/cars/{car}
+//	POST   /simulations/{id}/zones/{zone}/cars/{car}/calls
+//	POST   /simulations/{id}/zones/{zone}/cars/{car}/fire-service
+//
+// Floors are given and returned by their labels, such as "B1" or "G", and directions as "up" or "down".
+// Errors are returned as {"error": "..."} with a 4xx status.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/metrics"
+	"github.com/dshaneg/elevator/internal/scenario"
+	"github.com/dshaneg/elevator/internal/session"
+	"github.com/dshaneg/elevator/internal/tower"
+)
+
+// Server serves the simulations held in a session.Registry.
+type Server struct {
+	sessions *session.Registry
+	mux      *http.ServeMux
+	logger   *slog.Logger
+}
+
+// Option is a functional option type that allows us to configure the Server.
+type Option func(*Server)
+
+// WithLogger sets the logger that the dispatch decisions and events of the simulations it creates are
+// reported to. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// New creates a Server for the simulations in the Registry.
+func New(sessions *session.Registry, options ...Option) *Server {
+	s := Server{
+		sessions: sessions,
+		mux:      http.NewServeMux(),
+	}
+
+	for _, opt := range options {
+		opt(&s)
+	}
+
+	s.mux.HandleFunc("POST /simulations", s.create)
+	s.mux.HandleFunc("GET /simulations", s.list)
+	s.mux.HandleFunc("GET /simulations/{id}", s.withSession(s.get))
+	s.mux.HandleFunc("DELETE /simulations/{id}", s.delete)
+	s.mux.HandleFunc("POST /simulations/{id}/start", s.withSession(s.start))
+	s.mux.HandleFunc("POST /simulations/{id}/pause", s.withSession(s.pause))
+	s.mux.HandleFunc("POST /simulations/{id}/step", s.withSession(s.step))
+	s.mux.HandleFunc("PUT /simulations/{id}/speed", s.withSession(s.setSpeed))
+	s.mux.HandleFunc("GET /simulations/{id}/metrics", s.withSession(s.metrics))
+	s.mux.HandleFunc("GET /simulations/{id}/zones", s.withSession(s.zones))
+	s.mux.HandleFunc("GET /simulations/{id}/zones/{zone}", s.withSession(s.zone))
+	s.mux.HandleFunc("GET /simulations/{id}/zones/{zone}/landings", s.withSession(s.landings))
+	s.mux.HandleFunc("POST /simulations/{id}/zones/{zone}/hall-calls", s.withSession(s.hallCall))
+	s.mux.HandleFunc("POST /simulations/{id}/zones/{zone}/fire-recall", s.withSession(s.fireRecall))
+	s.mux.HandleFunc("POST /simulations/{id}/zones/{zone}/fire-restore", s.withSession(s.fireRestore))
+	s.mux.HandleFunc("GET /simulations/{id}/zones/{zone}/cars", s.withSession(s.cars))
+	s.mux.HandleFunc("GET /simulations/{id}/zones/{zone}/cars/{car}", s.withSession(s.car))
+	s.mux.HandleFunc("POST /simulations/{id}/zones/{zone}/cars/{car}/calls", s.withSession(s.carCall))
+	s.mux.HandleFunc("POST /simulations/{id}/zones/{zone}/cars/{car}/fire-service", s.withSession(s.fireService))
+
+	return &s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// createRequest is the body of POST /simulations.
+type createRequest struct {
+	Scenario     *scenario.Scenario `json:"scenario"` // the visitors' traffic; none if left out
+	Seed         uint64             `json:"seed"`
+	Regenerative bool               `json:"regenerative"`
+	EnergyWeight float64            `json:"energyWeight"`
+	Speed        float64            `json:"speed"` // 0 is taken as session.DefaultSpeed
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := decode(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	built, err := tower.New(tower.Config{
+		Seed:         req.Seed,
+		Visitors:     req.Scenario,
+		Regenerative: req.Regenerative,
+		EnergyWeight: req.EnergyWeight,
+		Logger:       s.logger,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	name := ""
+	if req.Scenario != nil {
+		name = req.Scenario.Name
+	}
+	sess, err := s.sessions.Create(name, built, req.Speed)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Location", "/simulations/"+sess.ID())
+	writeJSON(w, http.StatusCreated, newSimulationView(sess))
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	views := []simulationView{}
+	for _, sess := range s.sessions.List() {
+		views = append(views, newSimulationView(sess))
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	if !s.sessions.Delete(r.PathValue("id")) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("server: no simulation %q", r.PathValue("id")))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withSession looks up the simulation named in the path and calls the handler with its Session.
+func (s *Server) withSession(handler func(http.ResponseWriter, *http.Request, *session.Session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess, ok := s.sessions.Get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("server: no simulation %q", r.PathValue("id")))
+			return
+		}
+		handler(w, r, sess)
+	}
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	writeJSON(w, http.StatusOK, newSimulationView(sess))
+}
+
+func (s *Server) start(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	sess.Start()
+	writeJSON(w, http.StatusOK, newSimulationView(sess))
+}
+
+func (s *Server) pause(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	sess.Pause()
+	writeJSON(w, http.StatusOK, newSimulationView(sess))
+}
+
+// stepRequest is the body of POST /simulations/{id}/step, which may be left out to take a single step.
+type stepRequest struct {
+	Steps int `json:"steps"`
+}
+
+func (s *Server) step(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	req := stepRequest{Steps: 1}
+	if err := decode(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := sess.Step(req.Steps); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newSimulationView(sess))
+}
+
+// speedRequest is the body of PUT /simulations/{id}/speed.
+type speedRequest struct {
+	Speed float64 `json:"speed"`
+}
+
+func (s *Server) setSpeed(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	var req speedRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := sess.SetSpeed(req.Speed); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newSimulationView(sess))
+}
+
+// metrics serves the Prometheus metrics of the simulation; see package metrics.
+func (s *Server) metrics(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	metrics.Handler(sess.Inspect).ServeHTTP(w, r)
+}
+
+// simulationView is the JSON form of a simulation.
+type simulationView struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Now     time.Time `json:"now"`
+	Running bool      `json:"running"`
+	Speed   float64   `json:"speed"`
+}
+
+func newSimulationView(sess *session.Session) simulationView {
+	state := sess.State()
+	return simulationView{
+		ID:      state.ID,
+		Name:    state.Name,
+		Now:     state.Now,
+		Running: state.Running,
+		Speed:   state.Speed,
+	}
+}
+
+// decode reads the JSON body of the request into v, rejecting fields v does not have.
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return err
+		}
+		return fmt.Errorf("server: bad request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
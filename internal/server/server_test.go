@@ -0,0 +1,294 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/metrics"
+	"github.com/dshaneg/elevator/internal/server"
+	"github.com/dshaneg/elevator/internal/session"
+	"github.com/dshaneg/elevator/internal/tower"
+)
+
+// do sends a request with the given JSON body, if any, and decodes the JSON response into out, if given.
+func do(t *testing.T, srv *httptest.Server, method, path string, body any, out any) *http.Response {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req, err := http.NewRequest(method, srv.URL+path, &buf)
+	require.NoError(t, err)
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp
+}
+
+type simulation struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Now     time.Time `json:"now"`
+	Running bool      `json:"running"`
+	Speed   float64   `json:"speed"`
+}
+
+type landing struct {
+	Floor     string `json:"floor"`
+	Direction string `json:"direction"`
+	Status    string `json:"status"`
+	Car       *int   `json:"car"`
+}
+
+type carState struct {
+	Index     int      `json:"index"`
+	Floor     string   `json:"floor"`
+	Direction string   `json:"direction"`
+	Status    string   `json:"status"`
+	Mode      string   `json:"mode"`
+	Calls     []string `json:"calls"`
+}
+
+type zoneState struct {
+	Name     string     `json:"name"`
+	Mode     string     `json:"mode"`
+	Landings []landing  `json:"landings"`
+	Cars     []carState `json:"cars"`
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	sessions := session.NewRegistry()
+	srv := httptest.NewServer(server.New(sessions))
+	t.Cleanup(func() {
+		srv.Close()
+		sessions.Close()
+	})
+	return srv
+}
+
+func create(t *testing.T, srv *httptest.Server) simulation {
+	var sm simulation
+	resp := do(t, srv, http.MethodPost, "/simulations", map[string]any{
+		"scenario": map[string]any{"name": "quiet day", "traffic": "office", "scale": 0.1},
+		"seed":     7,
+	}, &sm)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/simulations/"+sm.ID, resp.Header.Get("Location"))
+	return sm
+}
+
+func TestSimulationLifecycle(t *testing.T) {
+	srv := newServer(t)
+
+	sm := create(t, srv)
+	assert.Equal(t, "quiet day", sm.Name)
+	assert.WithinDuration(t, tower.Start, sm.Now, 0)
+	assert.False(t, sm.Running)
+	assert.Equal(t, float64(session.DefaultSpeed), sm.Speed)
+
+	resp := do(t, srv, http.MethodPost, "/simulations/"+sm.ID+"/step", map[string]int{"steps": 3}, &sm)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.WithinDuration(t, tower.Start.Add(3*time.Minute), sm.Now, 0)
+
+	do(t, srv, http.MethodPost, "/simulations/"+sm.ID+"/step", nil, &sm)
+	assert.WithinDuration(t, tower.Start.Add(4*time.Minute), sm.Now, 0)
+
+	var list []simulation
+	do(t, srv, http.MethodGet, "/simulations", nil, &list)
+	assert.Len(t, list, 1)
+
+	resp = do(t, srv, http.MethodDelete, "/simulations/"+sm.ID, nil, nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = do(t, srv, http.MethodGet, "/simulations/"+sm.ID, nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestStartAndPause(t *testing.T) {
+	srv := newServer(t)
+	sm := create(t, srv)
+	path := "/simulations/" + sm.ID
+
+	// a minute a millisecond
+	do(t, srv, http.MethodPut, path+"/speed", map[string]float64{"speed": 60000}, &sm)
+	assert.Equal(t, 60000.0, sm.Speed)
+
+	do(t, srv, http.MethodPost, path+"/start", nil, &sm)
+	assert.True(t, sm.Running)
+
+	assert.Eventually(t, func() bool {
+		do(t, srv, http.MethodGet, path, nil, &sm)
+		return sm.Now.After(tower.Start.Add(5 * time.Minute))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	do(t, srv, http.MethodPost, path+"/pause", nil, &sm)
+	assert.False(t, sm.Running)
+	paused := sm.Now
+
+	time.Sleep(20 * time.Millisecond)
+	do(t, srv, http.MethodGet, path, nil, &sm)
+	assert.WithinDuration(t, paused, sm.Now, 0)
+}
+
+func TestHallCall(t *testing.T) {
+	srv := newServer(t)
+	sm := create(t, srv)
+	zone := "/simulations/" + sm.ID + "/zones/low-rise"
+
+	var l landing
+	resp := do(t, srv, http.MethodPost, zone+"/hall-calls", map[string]string{"floor": "5", "direction": "up"}, &l)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "5", l.Floor)
+	assert.Equal(t, "up", l.Direction)
+	assert.Equal(t, "waiting", l.Status)
+	require.NotNil(t, l.Car)
+
+	var landings []landing
+	do(t, srv, http.MethodGet, zone+"/landings", nil, &landings)
+	assert.Contains(t, landings, l)
+
+	var c carState
+	do(t, srv, http.MethodGet, zone+"/cars/"+strconv.Itoa(*l.Car), nil, &c)
+	assert.Equal(t, *l.Car, c.Index)
+	assert.Contains(t, c.Calls, "5")
+}
+
+func TestCarCall(t *testing.T) {
+	srv := newServer(t)
+	sm := create(t, srv)
+	zone := "/simulations/" + sm.ID + "/zones/high-rise"
+
+	var c carState
+	resp := do(t, srv, http.MethodPost, zone+"/cars/2/calls", map[string]string{"floor": "12"}, &c)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "9", c.Floor)
+	assert.Equal(t, []string{"12"}, c.Calls)
+
+	do(t, srv, http.MethodPost, "/simulations/"+sm.ID+"/step", nil, nil)
+
+	var cars []carState
+	do(t, srv, http.MethodGet, zone+"/cars", nil, &cars)
+	require.Len(t, cars, 3)
+	assert.Equal(t, "traveling", cars[2].Status)
+	assert.Equal(t, "10", cars[2].Floor)
+}
+
+func TestFireRecall(t *testing.T) {
+	srv := newServer(t)
+	sm := create(t, srv)
+	path := "/simulations/" + sm.ID
+	zone := path + "/zones/shuttle"
+
+	do(t, srv, http.MethodPost, zone+"/hall-calls", map[string]string{"floor": "G", "direction": "up"}, nil)
+
+	var z zoneState
+	resp := do(t, srv, http.MethodPost, zone+"/fire-recall", map[string]bool{"alternate": true}, &z)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "fire recall", z.Mode)
+	for _, l := range z.Landings {
+		assert.Equal(t, "idle", l.Status, "hall calls are cancelled by a recall")
+	}
+	for _, c := range z.Cars {
+		assert.Equal(t, "fire recall", c.Mode)
+		assert.Equal(t, "up", c.Direction, "cars are recalled to the alternate floor")
+	}
+
+	resp = do(t, srv, http.MethodPost, zone+"/hall-calls", map[string]string{"floor": "G", "direction": "up"}, nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "no car answers a hall call during a recall")
+
+	do(t, srv, http.MethodPost, path+"/step", map[string]int{"steps": 15}, nil)
+	do(t, srv, http.MethodGet, zone, nil, &z)
+	for _, c := range z.Cars {
+		assert.Equal(t, "9", c.Floor)
+	}
+
+	var c carState
+	resp = do(t, srv, http.MethodPost, zone+"/cars/1/fire-service", nil, &c)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "fire service", c.Mode)
+
+	do(t, srv, http.MethodGet, zone, nil, &z)
+	assert.Equal(t, "fire service", z.Mode)
+
+	resp = do(t, srv, http.MethodPost, zone+"/fire-restore", nil, &z)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "normal", z.Mode)
+	for _, c := range z.Cars {
+		assert.Equal(t, "normal", c.Mode)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	srv := newServer(t)
+	sm := create(t, srv)
+	path := "/simulations/" + sm.ID
+
+	do(t, srv, http.MethodPost, path+"/zones/low-rise/hall-calls", map[string]string{"floor": "5", "direction": "down"}, nil)
+
+	resp, err := http.Get(srv.URL + path + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, metrics.ContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), `simuvator_hall_call_pending{zone="low-rise",floor="5",direction="down"} 1`+"\n")
+	assert.Contains(t, string(body), `simuvator_car_capacity{zone="high-rise",car="2"}`)
+}
+
+func TestErrors(t *testing.T) {
+	srv := newServer(t)
+	sm := create(t, srv)
+	path := "/simulations/" + sm.ID
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		status int
+	}{
+		{"unknown simulation", http.MethodGet, "/simulations/99", nil, http.StatusNotFound},
+		{"unknown traffic", http.MethodPost, "/simulations", map[string]any{"scenario": map[string]any{"traffic": "stadium"}}, http.StatusBadRequest},
+		{"unknown field", http.MethodPost, "/simulations", map[string]any{"floors": 40}, http.StatusBadRequest},
+		{"no steps", http.MethodPost, path + "/step", map[string]int{"steps": 0}, http.StatusBadRequest},
+		{"too many steps", http.MethodPost, path + "/step", map[string]int{"steps": session.MaxSteps + 1}, http.StatusBadRequest},
+		{"negative speed", http.MethodPost, "/simulations", map[string]any{"speed": -1}, http.StatusBadRequest},
+		{"no speed", http.MethodPut, path + "/speed", map[string]float64{"speed": 0}, http.StatusBadRequest},
+		{"unknown zone", http.MethodGet, path + "/zones/penthouse", nil, http.StatusNotFound},
+		{"unknown car", http.MethodGet, path + "/zones/shuttle/cars/2", nil, http.StatusNotFound},
+		{"unknown floor", http.MethodPost, path + "/zones/low-rise/hall-calls", map[string]string{"floor": "13", "direction": "up"}, http.StatusBadRequest},
+		{"floor not served", http.MethodPost, path + "/zones/shuttle/hall-calls", map[string]string{"floor": "5", "direction": "up"}, http.StatusBadRequest},
+		{"bad direction", http.MethodPost, path + "/zones/low-rise/hall-calls", map[string]string{"floor": "5", "direction": "sideways"}, http.StatusBadRequest},
+		{"car call to a floor not served", http.MethodPost, path + "/zones/high-rise/cars/0/calls", map[string]string{"floor": "G"}, http.StatusBadRequest},
+		{"fire recall of an unknown zone", http.MethodPost, path + "/zones/penthouse/fire-recall", nil, http.StatusNotFound},
+		{"fire service before a recall", http.MethodPost, path + "/zones/low-rise/cars/0/fire-service", nil, http.StatusConflict},
+		{"fire service of an unknown car", http.MethodPost, path + "/zones/shuttle/cars/2/fire-service", nil, http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body map[string]string
+			resp := do(t, srv, tc.method, tc.path, tc.body, &body)
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.NotEmpty(t, body["error"])
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/floorplan"
+	"github.com/dshaneg/elevator/internal/session"
+	"github.com/dshaneg/elevator/internal/sim"
+)
+
+// zoneView is the JSON form of a building.Zone and the state of its Bank.
+type zoneView struct {
+	Name     string        `json:"name"`
+	Mode     string        `json:"mode"`
+	Floors   []string      `json:"floors"`
+	Landings []landingView `json:"landings"`
+	Cars     []carView     `json:"cars"`
+}
+
+// landingView is the JSON form of the hall call button for one direction at a floor.
+type landingView struct {
+	Floor     string `json:"floor"`
+	Direction string `json:"direction"`
+	Status    string `json:"status"`
+	Car       *int   `json:"car"` // the car assigned to the call, or loading at the landing; null if none
+}
+
+// carView is the JSON form of a car.
+type carView struct {
+	Index     int      `json:"index"`
+	Floor     string   `json:"floor"`
+	Direction string   `json:"direction"`
+	Status    string   `json:"status"`
+	Door      string   `json:"door"`
+	Mode      string   `json:"mode"`
+	Fault     string   `json:"fault"`
+	Load      int      `json:"load"`
+	Capacity  int      `json:"capacity"`
+	Calls     []string `json:"calls"`
+}
+
+func (s *Server) zones(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	views := []zoneView{}
+	sess.Inspect(func(sm *sim.Simulation) {
+		b := sm.Building()
+		for _, z := range b.Zones() {
+			views = append(views, newZoneView(b.Plan(), z))
+		}
+	})
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) zone(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	sess.Inspect(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, newZoneView(sm.Building().Plan(), z))
+	})
+}
+
+func (s *Server) landings(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	sess.Inspect(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, newLandingViews(sm.Building().Plan(), z))
+	})
+}
+
+func (s *Server) cars(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	sess.Inspect(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, newCarViews(sm.Building().Plan(), z.Bank))
+	})
+}
+
+func (s *Server) car(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	sess.Inspect(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		i, ok := findCar(w, r, z)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, newCarView(sm.Building().Plan(), z.Bank, i))
+	})
+}
+
+// hallCallRequest is the body of POST /simulations/{id}/zones/{zone}/hall-calls.
+type hallCallRequest struct {
+	Floor     string `json:"floor"`
+	Direction string `json:"direction"`
+}
+
+// hallCall presses the hall call button, as a passenger at the landing does, and returns the landing with the
+// car assigned to the call. A call that no car can answer, as during a fire recall, is a conflict.
+func (s *Server) hallCall(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	var req hallCallRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var direction car.Direction
+	switch req.Direction {
+	case "up":
+		direction = car.Up
+	case "down":
+		direction = car.Down
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("server: direction %q is not up or down", req.Direction))
+		return
+	}
+
+	sess.Do(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		plan := sm.Building().Plan()
+		floor, err := zoneFloor(plan, z, req.Floor)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		carIndex, err := z.Bank.Call(floor, direction)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if carIndex == bank.NoCar {
+			writeError(w, http.StatusConflict, fmt.Errorf("server: no car in zone %q can answer a call at %s", z.Name, req.Floor))
+			return
+		}
+		writeJSON(w, http.StatusOK, newLandingView(plan, z.Bank, floor, direction))
+	})
+}
+
+// carCallRequest is the body of POST /simulations/{id}/zones/{zone}/cars/{car}/calls.
+type carCallRequest struct {
+	Floor string `json:"floor"`
+}
+
+// carCall presses the car's button for a floor, as a passenger aboard does, and returns the car.
+func (s *Server) carCall(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	var req carCallRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sess.Do(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		i, ok := findCar(w, r, z)
+		if !ok {
+			return
+		}
+		plan := sm.Building().Plan()
+		floor, err := zoneFloor(plan, z, req.Floor)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if _, err := z.Bank.Car(i).Press(floor); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newCarView(plan, z.Bank, i))
+	})
+}
+
+// fireRecallRequest is the body of POST /simulations/{id}/zones/{zone}/fire-recall.
+type fireRecallRequest struct {
+	Alternate bool `json:"alternate"` // recall to the alternate floor, as when the fire is on the designated one
+}
+
+// fireRecall starts Phase I emergency recall of the zone's cars and returns the zone.
+func (s *Server) fireRecall(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	var req fireRecallRequest
+	if err := decode(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sess.Do(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		z.Bank.Recall(req.Alternate)
+		writeJSON(w, http.StatusOK, newZoneView(sm.Building().Plan(), z))
+	})
+}
+
+// fireService hands a recalled car over to a firefighter and returns the car. The zone must already be
+// in recall.
+func (s *Server) fireService(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	sess.Do(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		i, ok := findCar(w, r, z)
+		if !ok {
+			return
+		}
+		if err := z.Bank.FireService(i); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newCarView(sm.Building().Plan(), z.Bank, i))
+	})
+}
+
+// fireRestore returns the zone and its cars to normal operation and returns the zone.
+func (s *Server) fireRestore(w http.ResponseWriter, r *http.Request, sess *session.Session) {
+	sess.Do(func(sm *sim.Simulation) {
+		z, ok := findZone(w, r, sm)
+		if !ok {
+			return
+		}
+		z.Bank.Restore()
+		writeJSON(w, http.StatusOK, newZoneView(sm.Building().Plan(), z))
+	})
+}
+
+// findZone looks up the zone named in the path, writing a 404 if there is none.
+func findZone(w http.ResponseWriter, r *http.Request, sm *sim.Simulation) (*building.Zone, bool) {
+	z, ok := sm.Building().Zone(r.PathValue("zone"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("server: no zone %q", r.PathValue("zone")))
+	}
+	return z, ok
+}
+
+// findCar looks up the car indexed in the path, writing a 404 if there is none.
+func findCar(w http.ResponseWriter, r *http.Request, z *building.Zone) (int, bool) {
+	i, err := strconv.Atoi(r.PathValue("car"))
+	if err != nil || i < 0 || i >= z.Bank.NumCars() {
+		writeError(w, http.StatusNotFound, fmt.Errorf("server: no car %q in zone %q", r.PathValue("car"), z.Name))
+		return 0, false
+	}
+	return i, true
+}
+
+// zoneFloor returns the index of the floor with the given label, which the zone must serve.
+func zoneFloor(plan *floorplan.Plan, z *building.Zone, label string) (int, error) {
+	floor, err := plan.Index(label)
+	if err != nil {
+		return 0, err
+	}
+	if !z.Serves(floor) {
+		return 0, fmt.Errorf("server: zone %q does not serve floor %s", z.Name, label)
+	}
+	return floor, nil
+}
+
+func newZoneView(plan *floorplan.Plan, z *building.Zone) zoneView {
+	v := zoneView{
+		Name:     z.Name,
+		Mode:     z.Bank.Mode().String(),
+		Floors:   []string{},
+		Landings: newLandingViews(plan, z),
+		Cars:     newCarViews(plan, z.Bank),
+	}
+	for _, floor := range z.Floors {
+		v.Floors = append(v.Floors, plan.Label(floor))
+	}
+	return v
+}
+
+func newLandingViews(plan *floorplan.Plan, z *building.Zone) []landingView {
+	views := []landingView{}
+	for _, l := range z.Landings() {
+		views = append(views, newLandingView(plan, z.Bank, l.Floor, l.Direction))
+	}
+	return views
+}
+
+func newLandingView(plan *floorplan.Plan, b *bank.Bank, floor int, direction car.Direction) landingView {
+	status, c := b.Status(floor, direction)
+	v := landingView{
+		Floor:     plan.Label(floor),
+		Direction: direction.String(),
+		Status:    status.String(),
+	}
+
+	carIndex := b.Assigned(floor, direction)
+	if c != nil {
+		carIndex = b.Index(c)
+	}
+	if carIndex != bank.NoCar {
+		v.Car = &carIndex
+	}
+	return v
+}
+
+func newCarViews(plan *floorplan.Plan, b *bank.Bank) []carView {
+	views := []carView{}
+	for i := range b.NumCars() {
+		views = append(views, newCarView(plan, b, i))
+	}
+	return views
+}
+
+func newCarView(plan *floorplan.Plan, b *bank.Bank, i int) carView {
+	c := b.Car(i)
+	v := carView{
+		Index:     i,
+		Floor:     plan.Label(c.Floor()),
+		Direction: c.Direction().String(),
+		Status:    c.Status().String(),
+		Door:      c.Door().String(),
+		Mode:      c.Mode().String(),
+		Fault:     c.Fault().String(),
+		Load:      c.Load(),
+		Capacity:  c.Capacity(),
+		Calls:     []string{},
+	}
+	for _, floor := range c.Calls() {
+		v.Calls = append(v.Calls, plan.Label(floor))
+	}
+	return v
+}
@@ -0,0 +1,284 @@
+// Package session runs simulations on behalf of the simulator's network APIs: it keeps the simulations
+// that have been created, steps each in real time at its own speed, and tells watchers when one has stepped.
+// A Session guards its Simulation, so the APIs can step and inspect it from many goroutines at once.
+package session
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/sim"
+)
+
+// DefaultSpeed runs a simulation sixty times faster than real time, one step of a minute each second.
+const DefaultSpeed = 60
+
+// MaxSteps is the most steps that can be asked for at once: a day of simulated time. Larger requests would
+// hold the Simulation, and everyone else waiting on it, for as long as they took.
+const MaxSteps = 24 * 60
+
+// ErrSpeed is returned for a speed that is not positive.
+var ErrSpeed = errors.New("session: speed must be positive")
+
+// ErrSteps is returned for a number of steps that is not from 1 to MaxSteps.
+var ErrSteps = fmt.Errorf("session: steps must be from 1 to %d", MaxSteps)
+
+// Session is a Simulation and the loop that runs it.
+type Session struct {
+	id   string
+	name string
+
+	mu       sync.Mutex
+	sim      *sim.Simulation
+	speed    float64
+	running  bool
+	stop     chan struct{} // closed to stop the loop while running
+	watchers map[chan struct{}]bool
+	done     chan struct{} // closed once the Session has been deleted
+}
+
+// State is a summary of a Session: its clock and how it is running.
+type State struct {
+	ID      string
+	Name    string
+	Now     time.Time
+	Running bool
+	Speed   float64
+}
+
+// ID returns the identifier the Session was registered under.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// State returns a summary of the Session.
+func (s *Session) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return State{ID: s.id, Name: s.name, Now: s.sim.Now(), Running: s.running, Speed: s.speed}
+}
+
+// Do calls f with the Simulation, which it must not keep, while no one else is using it.
+// Anything f does to the Simulation is seen by watchers as a change.
+func (s *Session) Do(f func(*sim.Simulation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f(s.sim)
+	s.notify()
+}
+
+// Inspect calls f with the Simulation, which it must not keep or change, while no one else is using it.
+func (s *Session) Inspect(f func(*sim.Simulation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f(s.sim)
+}
+
+// Step steps the Simulation the given number of times straight away, whether or not it is running.
+func (s *Session) Step(steps int) error {
+	if steps < 1 || steps > MaxSteps {
+		return ErrSteps
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for range steps {
+		s.sim.Step()
+	}
+	s.notify()
+	return nil
+}
+
+// Start steps the Simulation in real time, scaled by its speed, until it is paused.
+func (s *Session) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+	go s.run(s.stop)
+}
+
+// Pause stops the Simulation stepping in real time.
+func (s *Session) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pause()
+}
+
+// SetSpeed sets how many times faster than real time the Simulation runs. A change of speed takes effect
+// from the next step.
+func (s *Session) SetSpeed(speed float64) error {
+	if speed <= 0 {
+		return ErrSpeed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.speed = speed
+	return nil
+}
+
+// Watch returns a channel that receives whenever the Simulation has changed, and a function to stop watching.
+// Changes that come while the watcher is busy are merged, so a slow watcher sees the latest state rather than
+// every one in between.
+func (s *Session) Watch() (<-chan struct{}, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := make(chan struct{}, 1)
+	s.watchers[c] = true
+	return c, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.watchers, c)
+	}
+}
+
+// Done returns a channel that is closed once the Session has been deleted from its Registry.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// run steps the Simulation until stop is closed.
+func (s *Session) run(stop <-chan struct{}) {
+	for {
+		s.mu.Lock()
+		interval := time.Duration(float64(s.sim.TimeStep()) / s.speed)
+		s.mu.Unlock()
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		s.mu.Lock()
+		select {
+		case <-stop:
+			// paused while waiting for the lock
+		default:
+			s.sim.Step()
+			s.notify()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// pause stops the loop if it is running. The caller holds the lock.
+func (s *Session) pause() {
+	if !s.running {
+		return
+	}
+	close(s.stop)
+	s.running = false
+}
+
+// notify tells the watchers the Simulation has changed. The caller holds the lock.
+func (s *Session) notify() {
+	for c := range s.watchers {
+		select {
+		case c <- struct{}{}:
+		default:
+			// the watcher has yet to catch up with the last change
+		}
+	}
+}
+
+// Registry holds the sessions created through the APIs, each under its own ID.
+type Registry struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	nextID   int
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{sessions: map[string]*Session{}}
+}
+
+// Create registers a new, paused Session of the Simulation. A speed of 0 is taken as DefaultSpeed.
+func (r *Registry) Create(name string, s *sim.Simulation, speed float64) (*Session, error) {
+	if speed < 0 {
+		return nil, ErrSpeed
+	}
+	if speed == 0 {
+		speed = DefaultSpeed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	sess := &Session{
+		id:       strconv.Itoa(r.nextID),
+		name:     name,
+		sim:      s,
+		speed:    speed,
+		watchers: map[chan struct{}]bool{},
+		done:     make(chan struct{}),
+	}
+	r.sessions[sess.id] = sess
+	return sess, nil
+}
+
+// Get returns the Session with the given ID.
+func (r *Registry) Get(id string) (*Session, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sess, ok := r.sessions[id]
+	return sess, ok
+}
+
+// List returns every Session in the order they were created.
+func (r *Registry) List() []*Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessions := make([]*Session, 0, len(r.sessions))
+	for _, sess := range r.sessions {
+		sessions = append(sessions, sess)
+	}
+	slices.SortFunc(sessions, func(a, b *Session) int {
+		ai, _ := strconv.Atoi(a.id)
+		bi, _ := strconv.Atoi(b.id)
+		return ai - bi
+	})
+	return sessions
+}
+
+// Delete pauses the Session with the given ID and forgets it, returning false if there is none.
+func (r *Registry) Delete(id string) bool {
+	r.mu.Lock()
+	sess, ok := r.sessions[id]
+	delete(r.sessions, id)
+	r.mu.Unlock()
+
+	if ok {
+		sess.Pause()
+		close(sess.done)
+		sess.Do(func(s *sim.Simulation) { s.Close() })
+	}
+	return ok
+}
+
+// Close pauses every Session, so that none is left stepping in the background.
+func (r *Registry) Close() {
+	for _, sess := range r.List() {
+		sess.Pause()
+	}
+}
@@ -0,0 +1,128 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/session"
+	"github.com/dshaneg/elevator/internal/sim"
+	"github.com/dshaneg/elevator/internal/tower"
+)
+
+func newSimulation(t *testing.T) *sim.Simulation {
+	s, err := tower.New(tower.Config{})
+	require.NoError(t, err)
+	return s
+}
+
+func TestRegistry(t *testing.T) {
+	r := session.NewRegistry()
+	defer r.Close()
+
+	first, err := r.Create("first", newSimulation(t), 0)
+	require.NoError(t, err)
+	second, err := r.Create("second", newSimulation(t), 120)
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(session.DefaultSpeed), first.State().Speed)
+	assert.Equal(t, 120.0, second.State().Speed)
+	assert.Equal(t, []*session.Session{first, second}, r.List())
+
+	got, ok := r.Get(second.ID())
+	assert.True(t, ok)
+	assert.Same(t, second, got)
+
+	assert.True(t, r.Delete(first.ID()))
+	assert.False(t, r.Delete(first.ID()))
+	_, ok = r.Get(first.ID())
+	assert.False(t, ok)
+	assert.Equal(t, []*session.Session{second}, r.List())
+
+	select {
+	case <-first.Done():
+	default:
+		t.Error("a deleted session should be done")
+	}
+
+	_, err = r.Create("backwards", newSimulation(t), -1)
+	assert.ErrorIs(t, err, session.ErrSpeed)
+}
+
+func TestStartAndPause(t *testing.T) {
+	r := session.NewRegistry()
+	defer r.Close()
+	sess, err := r.Create("", newSimulation(t), 0)
+	require.NoError(t, err)
+
+	// a minute a millisecond
+	require.NoError(t, sess.SetSpeed(60000))
+	assert.ErrorIs(t, sess.SetSpeed(0), session.ErrSpeed)
+
+	sess.Start()
+	sess.Start()
+	assert.True(t, sess.State().Running)
+	assert.Eventually(t, func() bool {
+		return sess.State().Now.After(tower.Start.Add(5 * time.Minute))
+	}, 5*time.Second, time.Millisecond)
+
+	sess.Pause()
+	paused := sess.State()
+	assert.False(t, paused.Running)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, paused.Now, sess.State().Now)
+}
+
+func TestStep(t *testing.T) {
+	r := session.NewRegistry()
+	defer r.Close()
+	sess, err := r.Create("", newSimulation(t), 0)
+	require.NoError(t, err)
+
+	require.NoError(t, sess.Step(session.MaxSteps))
+	assert.Equal(t, tower.Start.Add(24*time.Hour), sess.State().Now)
+
+	for _, steps := range []int{0, -1, session.MaxSteps + 1} {
+		assert.ErrorIs(t, sess.Step(steps), session.ErrSteps, steps)
+	}
+	assert.Equal(t, tower.Start.Add(24*time.Hour), sess.State().Now, "a rejected step leaves the clock alone")
+}
+
+func TestWatchMergesChanges(t *testing.T) {
+	r := session.NewRegistry()
+	defer r.Close()
+	sess, err := r.Create("", newSimulation(t), 0)
+	require.NoError(t, err)
+
+	changes, stop := sess.Watch()
+
+	require.NoError(t, sess.Step(1))
+	require.NoError(t, sess.Step(2))
+	sess.Do(func(*sim.Simulation) {})
+
+	<-changes
+	select {
+	case <-changes:
+		t.Error("changes made while the watcher was busy should be merged")
+	default:
+	}
+	assert.Equal(t, tower.Start.Add(3*time.Minute), sess.State().Now)
+
+	sess.Inspect(func(*sim.Simulation) {})
+	select {
+	case <-changes:
+		t.Error("inspecting the simulation is not a change")
+	default:
+	}
+
+	stop()
+	require.NoError(t, sess.Step(1))
+	select {
+	case <-changes:
+		t.Error("a watcher who stopped should not be told of changes")
+	default:
+	}
+}
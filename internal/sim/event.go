@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+)
+
+// Event is something scheduled to happen to the Building at a point in simulated time.
+type Event struct {
+	At     time.Time
+	Name   string
+	Action func(*building.Building) error
+}
+
+// FireRecall returns an Event that starts Phase I recall of the bank serving the named zone,
+// sending its cars to the alternate recall floor if alternate is set.
+func FireRecall(at time.Time, zone string, alternate bool) Event {
+	return Event{
+		At:   at,
+		Name: fmt.Sprintf("fire recall %s", zone),
+		Action: withZone(zone, func(z *building.Zone) error {
+			z.Bank.Recall(alternate)
+			return nil
+		}),
+	}
+}
+
+// FireService returns an Event that hands the car at the given index in the named zone to a firefighter
+// for Phase II operation.
+func FireService(at time.Time, zone string, carIndex int) Event {
+	return Event{
+		At:   at,
+		Name: fmt.Sprintf("fire service %s car %d", zone, carIndex),
+		Action: withZone(zone, func(z *building.Zone) error {
+			return z.Bank.FireService(carIndex)
+		}),
+	}
+}
+
+// FireRestore returns an Event that returns the bank serving the named zone to normal operation.
+func FireRestore(at time.Time, zone string) Event {
+	return Event{
+		At:   at,
+		Name: fmt.Sprintf("fire restore %s", zone),
+		Action: withZone(zone, func(z *building.Zone) error {
+			z.Bank.Restore()
+			return nil
+		}),
+	}
+}
+
+func withZone(name string, action func(*building.Zone) error) func(*building.Building) error {
+	return func(b *building.Building) error {
+		z, ok := b.Zone(name)
+		if !ok {
+			return fmt.Errorf("sim: no zone named %q", name)
+		}
+		return action(z)
+	}
+}
+
+// CarMode returns an Event that switches the car at the given index in the named zone to an operating mode.
+func CarMode(at time.Time, zone string, carIndex int, mode car.Mode) Event {
+	return Event{
+		At:   at,
+		Name: fmt.Sprintf("%s car %d %s", zone, carIndex, mode),
+		Action: withZone(zone, func(z *building.Zone) error {
+			return z.Bank.SetMode(carIndex, mode)
+		}),
+	}
+}
+
+// MaintenanceWindow returns the events that take the car at the given index in the named zone out of service
+// at from and return it to normal operation at to.
+func MaintenanceWindow(from, to time.Time, zone string, carIndex int) []Event {
+	return []Event{
+		CarMode(from, zone, carIndex, car.OutOfService),
+		CarMode(to, zone, carIndex, car.Normal),
+	}
+}
@@ -0,0 +1,141 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/passenger"
+)
+
+// FaultModel describes how often cars break down and how long they take to fix.
+//
+// Time between failures and time to repair are both drawn from exponential distributions,
+// so a car is as likely to fail in its first hour as its hundredth.
+type FaultModel struct {
+	MTBF   time.Duration // mean time between failures of a single car, counted while it is in service
+	MTTR   time.Duration // mean time to repair a failed car
+	Faults []car.Fault   // the kinds of fault to choose from, all kinds if empty
+}
+
+// Entrapment records a breakdown of a car with passengers aboard, and how many it trapped.
+type Entrapment struct {
+	Zone       string
+	Car        int
+	Fault      car.Fault
+	At         time.Time
+	Released   time.Time // zero until the car is repaired
+	Passengers int       // how many passengers were aboard
+}
+
+// WithFaults breaks cars down at random according to the given model. The MTBF and MTTR must be positive.
+func WithFaults(model FaultModel) Option {
+	return func(s *Simulation) {
+		if model.MTBF <= 0 || model.MTTR <= 0 {
+			s.err = errors.Join(s.err, fmt.Errorf("sim: the MTBF and MTTR must be positive, not %v and %v", model.MTBF, model.MTTR))
+			return
+		}
+		if len(model.Faults) == 0 {
+			model.Faults = []car.Fault{car.DoorFault, car.Stall, car.LevellingError}
+		}
+		s.faults = &model
+	}
+}
+
+// WithSeed seeds the random numbers drawn by the Simulation, so that runs with the same seed are identical.
+func WithSeed(seed uint64) Option {
+	return func(s *Simulation) {
+		s.rand = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// repair is a car waiting for a technician.
+type repair struct {
+	zone       *building.Zone
+	carIndex   int
+	at         time.Time
+	entrapment int // index into entrapments, or -1 if the car was empty
+}
+
+// breakDown fails working cars at random, and repairs any whose time has come. A repair that fails is
+// tried again on the next step.
+func (s *Simulation) breakDown() {
+	remaining := s.repairs[:0]
+	for _, r := range s.repairs {
+		if r.at.After(s.clock) {
+			remaining = append(remaining, r)
+			continue
+		}
+		if err := r.zone.Bank.Repair(r.carIndex); err != nil {
+			s.logger.Error("repair failed", "zone", r.zone.Name, "car", r.carIndex, "error", err)
+			remaining = append(remaining, r)
+			continue
+		}
+		if r.entrapment >= 0 {
+			s.entrapments[r.entrapment].Released = s.clock
+		}
+		s.logger.Info("car repaired", "zone", r.zone.Name, "car", r.carIndex, "at", s.clock)
+	}
+	s.repairs = remaining
+
+	if s.faults == nil {
+		return
+	}
+
+	chance := 1 - math.Exp(-float64(s.step)/float64(s.faults.MTBF))
+	for _, z := range s.building.Zones() {
+		for i := range z.Bank.NumCars() {
+			c := z.Bank.Car(i)
+			if !inService(c.Mode(), c.Fault()) || s.rand.Float64() >= chance {
+				continue
+			}
+			s.fail(z, i, s.faults.Faults[s.rand.IntN(len(s.faults.Faults))])
+		}
+	}
+}
+
+func (s *Simulation) fail(z *building.Zone, carIndex int, fault car.Fault) {
+	if err := z.Bank.Fail(carIndex, fault); err != nil {
+		s.logger.Error("failure failed", "zone", z.Name, "car", carIndex, "error", err)
+		return
+	}
+
+	trapped := 0
+	for _, p := range s.passengers {
+		if p.Status() == passenger.Riding && z.Bank.Index(p.Car()) == carIndex {
+			trapped++
+		}
+	}
+
+	for i := range s.cars {
+		if s.cars[i].zone == z && s.cars[i].index == carIndex {
+			s.cars[i].failures++
+		}
+	}
+	entrapment := -1
+	if trapped > 0 {
+		s.entrapments = append(s.entrapments, Entrapment{
+			Zone:       z.Name,
+			Car:        carIndex,
+			Fault:      fault,
+			At:         s.clock,
+			Passengers: trapped,
+		})
+		entrapment = len(s.entrapments) - 1
+	}
+
+	mttr := s.rand.ExpFloat64() * float64(s.faults.MTTR)
+	at := s.clock.Add(max(time.Duration(mttr), s.step))
+	s.repairs = append(s.repairs, repair{zone: z, carIndex: carIndex, at: at, entrapment: entrapment})
+
+	s.logger.Info("car failed", "zone", z.Name, "car", carIndex, "fault", fault, "trapped", trapped, "at", s.clock, "repair", at)
+}
+
+// inService reports whether a car in the given mode and fault is available to carry passengers.
+func inService(mode car.Mode, fault car.Fault) bool {
+	return fault == car.NoFault && mode != car.OutOfService && mode != car.Inspection
+}
@@ -0,0 +1,199 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/invariant"
+)
+
+// Report summarizes how well the Building served its passengers over a Simulation.
+type Report struct {
+	Start     time.Time
+	End       time.Time
+	Rides     int           // rides boarded; a trip with a transfer is two rides
+	Abandoned int           // waits that ended without boarding, whether given up on or cut short by an evacuation
+	Reneged   int           // waits given up on by passengers who ran out of patience
+	Balked    int           // trips put off because the landing was too crowded
+	Stairs    int           // trips walked by the stairs
+	Waiting   int           // passengers still waiting when the report was taken
+	MeanWait  time.Duration // the average wait for a car across all rides
+	P90Wait   time.Duration // 90% of rides waited no longer than this
+	MaxWait   time.Duration // the longest wait for a car
+
+	Cars        []CarReport
+	Entrapments []Entrapment          // breakdowns with passengers aboard
+	Violations  []invariant.Violation // of the invariants, if the Simulation has a Checker
+}
+
+// WaitBuckets are the upper bounds of the buckets of a WaitHistogram. Waits are measured in whole steps of
+// the Simulation, a minute by default.
+var WaitBuckets = []time.Duration{
+	time.Minute, 2 * time.Minute, 3 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+}
+
+// WaitHistogram counts the waits for a car of the passengers who boarded one.
+type WaitHistogram struct {
+	Buckets []int         // the waits no longer than each of WaitBuckets
+	Count   int           // all the waits, however long
+	Sum     time.Duration // the waits added up
+}
+
+func (h *WaitHistogram) observe(wait time.Duration) {
+	for i, bound := range WaitBuckets {
+		if wait <= bound {
+			h.Buckets[i]++
+		}
+	}
+	h.Count++
+	h.Sum += wait
+}
+
+// CarReport summarizes the service of a single car.
+type CarReport struct {
+	Zone         string
+	Car          int
+	Availability float64 // the fraction of the Simulation the car was in service, from 0 to 1
+	Failures     int
+	Downtime     time.Duration // time out of service, whether broken down or taken out for maintenance
+	Energy       car.Meter
+}
+
+// Energy returns the combined energy readings of every car in the Building.
+func (r Report) Energy() car.Meter {
+	total := car.Meter{}
+	for _, c := range r.Cars {
+		total.Motion += c.Energy.Motion
+		total.Regenerated += c.Energy.Regenerated
+		total.Doors += c.Energy.Doors
+		total.Standby += c.Energy.Standby
+		total.Trips += c.Energy.Trips
+		total.Passengers += c.Energy.Passengers
+	}
+	return total
+}
+
+// perTrip returns the net energy of the reading spread over its trips, in watt hours.
+func perTrip(m car.Meter) float64 {
+	if m.Trips == 0 {
+		return 0
+	}
+	return m.Total() * 1000 / float64(m.Trips)
+}
+
+// perPassenger returns the net energy of the reading spread over its passengers, in watt hours.
+func perPassenger(m car.Meter) float64 {
+	if m.Passengers == 0 {
+		return 0
+	}
+	return m.Total() * 1000 / float64(m.Passengers)
+}
+
+// Breakdowns returns the number of times a car broke down.
+func (r Report) Breakdowns() int {
+	breakdowns := 0
+	for _, c := range r.Cars {
+		breakdowns += c.Failures
+	}
+	return breakdowns
+}
+
+// TrappedPassengers returns the number of passengers trapped in broken down cars.
+func (r Report) TrappedPassengers() int {
+	trapped := 0
+	for _, e := range r.Entrapments {
+		trapped += e.Passengers
+	}
+	return trapped
+}
+
+// Report summarizes the Simulation so far.
+func (s *Simulation) Report() Report {
+	r := Report{
+		Start:     s.start,
+		End:       s.clock,
+		Rides:     len(s.waits),
+		Abandoned: s.abandoned,
+		Waiting:   len(s.waiting),
+
+		Entrapments: slices.Clone(s.entrapments),
+	}
+	if s.checker != nil {
+		r.Violations = slices.Clone(s.checker.Violations())
+	}
+
+	r.Reneged, r.Balked, r.Stairs = s.retired.Reneged, s.retired.Balked, s.retired.StairTrips
+	for _, p := range s.passengers {
+		stats := p.Stats()
+		r.Reneged += stats.Reneged
+		r.Balked += stats.Balked
+		r.Stairs += stats.StairTrips
+	}
+
+	for _, cs := range s.cars {
+		cr := CarReport{
+			Zone:     cs.zone.Name,
+			Car:      cs.index,
+			Downtime: cs.down,
+			Energy:   cs.zone.Bank.Car(cs.index).Energy(),
+		}
+		if total := cs.up + cs.down; total > 0 {
+			cr.Availability = float64(cs.up) / float64(total)
+		}
+		cr.Failures = cs.failures
+		r.Cars = append(r.Cars, cr)
+	}
+
+	if len(s.waits) == 0 {
+		return r
+	}
+
+	waits := slices.Clone(s.waits)
+	slices.Sort(waits)
+
+	var total time.Duration
+	for _, w := range waits {
+		total += w
+	}
+	r.MeanWait = total / time.Duration(len(waits))
+	r.P90Wait = waits[(len(waits)*9+9)/10-1]
+	r.MaxWait = waits[len(waits)-1]
+
+	return r
+}
+
+// Print writes the Report to w in a human readable form.
+func (r Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%v - %v\n", r.Start.Format(time.DateTime), r.End.Format(time.DateTime))
+	fmt.Fprintf(tw, "rides: %d  abandoned: %d  still waiting: %d\n", r.Rides, r.Abandoned, r.Waiting)
+	fmt.Fprintf(tw, "reneged: %d  balked: %d  took the stairs: %d\n", r.Reneged, r.Balked, r.Stairs)
+	fmt.Fprintf(tw, "wait mean: %v  p90: %v  max: %v\n", r.MeanWait, r.P90Wait, r.MaxWait)
+	fmt.Fprintf(tw, "breakdowns: %d  entrapments: %d  trapped passengers: %d\n", r.Breakdowns(), len(r.Entrapments), r.TrappedPassengers())
+	energy := r.Energy()
+	fmt.Fprintf(tw, "energy: %.2f kWh  per trip: %.1f Wh  per passenger: %.1f Wh  regenerated: %.2f kWh\n",
+		energy.Total(), perTrip(energy), perPassenger(energy), energy.Regenerated)
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "zone\tcar\tavailability\tfailures\tdowntime\tkWh\tWh/trip\tWh/passenger")
+	for _, c := range r.Cars {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t%d\t%v\t%.2f\t%.1f\t%.1f\n",
+			c.Zone, c.Car, c.Availability*100, c.Failures, c.Downtime,
+			c.Energy.Total(), perTrip(c.Energy), perPassenger(c.Energy))
+	}
+
+	if len(r.Violations) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintf(tw, "invariant violations: %d\n", len(r.Violations))
+		for _, v := range r.Violations {
+			fmt.Fprintln(tw, v)
+		}
+	}
+
+	return tw.Flush()
+}
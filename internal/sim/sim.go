@@ -0,0 +1,303 @@
+package sim
+
+import (
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/invariant"
+	"github.com/dshaneg/elevator/internal/passenger"
+)
+
+// Simulation steps a Building and the passengers who use it through simulated time,
+// firing the events on its timeline as their time comes.
+type Simulation struct {
+	building   *building.Building
+	passengers []*passenger.Passenger
+	clock      time.Time
+	step       time.Duration
+	timeline   []Event // events that have not fired yet, in time order
+	logger     *slog.Logger
+	start      time.Time
+	waiting    map[*passenger.Passenger]time.Time // when each waiting passenger pressed the hall call button
+	waits      []time.Duration                    // how long each passenger who boarded a car waited for it
+	histogram  WaitHistogram                      // the same waits, counted into WaitBuckets as they end
+	abandoned  int                                // waits that ended without boarding
+
+	rand        *rand.Rand
+	faults      *FaultModel
+	repairs     []repair
+	entrapments []Entrapment
+	cars        []carStats // per car service records, in zone order
+
+	arrivals []stream
+	retired  passenger.Stats // stats of passengers who have arrived and left the Simulation
+	checker  *invariant.Checker
+	err      error // why the options given to New are invalid, if they are
+}
+
+// stream is a source of one-off trips and the options for the passengers who make them.
+type stream struct {
+	source  arrivals.Source
+	options []passenger.Option
+}
+
+// carStats tracks how much of the Simulation a car spent able to carry passengers.
+type carStats struct {
+	zone     *building.Zone
+	index    int
+	up, down time.Duration
+	failures int
+}
+
+// Option is a functional option type that allows us to configure the Simulation.
+type Option func(*Simulation)
+
+// WithStart sets the simulated time the Simulation begins at.
+func WithStart(start time.Time) Option {
+	return func(s *Simulation) {
+		s.clock = start
+	}
+}
+
+// WithStep sets how much simulated time passes with each Step. The default is one minute.
+func WithStep(step time.Duration) Option {
+	return func(s *Simulation) {
+		s.step = step
+	}
+}
+
+// WithEvents schedules the given events on the timeline.
+func WithEvents(events ...Event) Option {
+	return func(s *Simulation) {
+		for _, e := range events {
+			s.Schedule(e)
+		}
+	}
+}
+
+// WithLogger sets the logger that fired events are reported to. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Simulation) {
+		s.logger = logger
+	}
+}
+
+// WithArrivals adds a stream of one-off trips to the Simulation. Each Trip brings a new Passenger to its
+// landing, configured with the given options, who leaves the Simulation once they have arrived.
+func WithArrivals(source arrivals.Source, options ...passenger.Option) Option {
+	return func(s *Simulation) {
+		s.arrivals = append(s.arrivals, stream{source, options})
+	}
+}
+
+// WithChecker checks the invariants of the Building at the end of every step, logging each violation as it is
+// found; the Report lists them all.
+func WithChecker(c *invariant.Checker) Option {
+	return func(s *Simulation) {
+		s.checker = c
+	}
+}
+
+// New creates a new Simulation of the given Building and passengers. It returns an error if an option is invalid,
+// such as a FaultModel with no MTBF.
+func New(b *building.Building, passengers []*passenger.Passenger, options ...Option) (*Simulation, error) {
+	s := Simulation{
+		building:   b,
+		passengers: passengers,
+		step:       time.Minute,
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		waiting:    map[*passenger.Passenger]time.Time{},
+		histogram:  WaitHistogram{Buckets: make([]int, len(WaitBuckets))},
+		rand:       rand.New(rand.NewPCG(0, 0)),
+	}
+
+	for _, opt := range options {
+		opt(&s)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.start = s.clock
+
+	for _, z := range b.Zones() {
+		for i := range z.Bank.NumCars() {
+			s.cars = append(s.cars, carStats{zone: z, index: i})
+		}
+	}
+
+	return &s, nil
+}
+
+// Now returns the current simulated time.
+func (s *Simulation) Now() time.Time {
+	return s.clock
+}
+
+// TimeStep returns how much simulated time passes with each Step.
+func (s *Simulation) TimeStep() time.Duration {
+	return s.step
+}
+
+// Building returns the Building being simulated.
+func (s *Simulation) Building() *building.Building {
+	return s.building
+}
+
+// Passengers returns the passengers being simulated.
+func (s *Simulation) Passengers() []*passenger.Passenger {
+	return s.passengers
+}
+
+// WaitHistogram returns the waits for a car of the passengers who have boarded one, counted into WaitBuckets.
+// Unlike Report, it takes no longer however long the Simulation has run.
+func (s *Simulation) WaitHistogram() WaitHistogram {
+	h := s.histogram
+	h.Buckets = slices.Clone(h.Buckets)
+	return h
+}
+
+// Waiting returns the number of passengers waiting for a car.
+func (s *Simulation) Waiting() int {
+	return len(s.waiting)
+}
+
+// Abandoned returns the number of waits for a car that ended without boarding.
+func (s *Simulation) Abandoned() int {
+	return s.abandoned
+}
+
+// AddPassenger brings a Passenger into the Simulation, who is ticked from the next Step.
+func (s *Simulation) AddPassenger(p *passenger.Passenger) {
+	s.passengers = append(s.passengers, p)
+}
+
+// Schedule adds an event to the timeline. Events scheduled for the same time fire in the order they were scheduled.
+func (s *Simulation) Schedule(e Event) {
+	i, _ := slices.BinarySearchFunc(s.timeline, e.At, func(scheduled Event, at time.Time) int {
+		if scheduled.At.After(at) {
+			return 1
+		}
+		return -1
+	})
+	s.timeline = slices.Insert(s.timeline, i, e)
+}
+
+// Step advances the clock, fires any events that have come due, then ticks the Building and every passenger,
+// and checks the invariants if the Simulation has a Checker.
+func (s *Simulation) Step() {
+	s.clock = s.clock.Add(s.step)
+
+	for len(s.timeline) > 0 && !s.timeline[0].At.After(s.clock) {
+		e := s.timeline[0]
+		s.timeline = s.timeline[1:]
+		s.fire(e)
+	}
+	s.breakDown()
+	s.arrive()
+
+	s.building.Tick()
+	for _, p := range s.passengers {
+		p.Tick(s.clock)
+		s.observe(p)
+	}
+	s.retire()
+
+	for i := range s.cars {
+		cs := &s.cars[i]
+		c := cs.zone.Bank.Car(cs.index)
+		if inService(c.Mode(), c.Fault()) {
+			cs.up += s.step
+		} else {
+			cs.down += s.step
+		}
+		if c.Status() == car.Parked && c.Door() == car.Closed {
+			c.AddStandby(s.step)
+		}
+	}
+
+	if s.checker != nil {
+		for _, v := range s.checker.Check(s.building, s.clock) {
+			s.logger.Warn("invariant violated", "rule", v.Rule, "zone", v.Zone, "car", v.Car, "floor", v.Floor, "detail", v.Detail)
+		}
+	}
+}
+
+// Run steps the Simulation until the clock reaches the given time.
+func (s *Simulation) Run(until time.Time) {
+	for s.clock.Before(until) {
+		s.Step()
+	}
+}
+
+// Close releases what the Simulation's banks hold, such as the goroutines of cars run as Actors.
+// The Simulation must not be used afterwards.
+func (s *Simulation) Close() {
+	for _, z := range s.building.Zones() {
+		z.Bank.Close()
+	}
+}
+
+// arrive brings in a Passenger for each Trip that has come due.
+func (s *Simulation) arrive() {
+	for _, a := range s.arrivals {
+		for _, trip := range a.source.Until(s.clock) {
+			options := append([]passenger.Option{passenger.WithFloor(trip.From), passenger.WithTrip(trip.To)}, a.options...)
+			p, err := passenger.New(s.building, options...)
+			if err != nil {
+				s.logger.Warn("arrival skipped", "error", err, "at", s.clock)
+				continue
+			}
+			s.passengers = append(s.passengers, p)
+		}
+	}
+}
+
+// retire removes the passengers who have finished their one-off trips, keeping their stats for the Report.
+func (s *Simulation) retire() {
+	s.passengers = slices.DeleteFunc(s.passengers, func(p *passenger.Passenger) bool {
+		if p.Status() != passenger.Arrived {
+			return false
+		}
+		s.retired.StairTrips += p.Stats().StairTrips
+		s.retired.Balked += p.Stats().Balked
+		s.retired.Reneged += p.Stats().Reneged
+		return true
+	})
+}
+
+// observe records the start and end of the Passenger's waits for a car.
+func (s *Simulation) observe(p *passenger.Passenger) {
+	after := p.Status()
+	since, waiting := s.waiting[p]
+
+	switch {
+	case waiting && after == passenger.Riding:
+		wait := s.clock.Sub(since)
+		s.waits = append(s.waits, wait)
+		s.histogram.observe(wait)
+		delete(s.waiting, p)
+	case waiting && !isWaiting(after):
+		s.abandoned++
+		delete(s.waiting, p)
+	case !waiting && isWaiting(after):
+		s.waiting[p] = s.clock
+	}
+}
+
+func isWaiting(status passenger.Status) bool {
+	return status == passenger.WaitingUp || status == passenger.WaitingDown
+}
+
+func (s *Simulation) fire(e Event) {
+	if err := e.Action(s.building); err != nil {
+		s.logger.Error("event failed", "event", e.Name, "at", e.At, "error", err)
+		return
+	}
+	s.logger.Info("event", "event", e.Name, "at", e.At)
+}
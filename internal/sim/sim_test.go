@@ -0,0 +1,317 @@
+package sim_test
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/floorplan"
+	"github.com/dshaneg/elevator/internal/invariant"
+	"github.com/dshaneg/elevator/internal/passenger"
+	"github.com/dshaneg/elevator/internal/sim"
+)
+
+// tue0800AM is the start of a working day.
+var tue0800AM = time.Date(2024, 11, 19, 8, 0, 0, 0, time.Local)
+
+func newBuilding(t *testing.T, numFloors int) *building.Building {
+	cars := []bank.Member{}
+	for range 2 {
+		c, err := car.NewCar(numFloors)
+		require.NoError(t, err)
+		cars = append(cars, c)
+	}
+	bk, err := bank.New(numFloors, cars, bank.WithRecallFloors(0, 1))
+	assert.NoError(t, err)
+
+	b, err := building.New(floorplan.Numbered(numFloors),
+		building.Zone{Name: "main", Bank: bk, Floors: building.Span(0, numFloors-1)},
+	)
+	assert.NoError(t, err)
+	return b
+}
+
+func newSim(t *testing.T, b *building.Building, passengers []*passenger.Passenger, options ...sim.Option) *sim.Simulation {
+	t.Helper()
+	s, err := sim.New(b, passengers, options...)
+	require.NoError(t, err)
+	return s
+}
+
+func TestEventsFireInTimeOrder(t *testing.T) {
+	fired := []string{}
+	record := func(at time.Time, name string) sim.Event {
+		return sim.Event{At: at, Name: name, Action: func(*building.Building) error {
+			fired = append(fired, name)
+			return nil
+		}}
+	}
+
+	s := newSim(t, newBuilding(t, 5), nil,
+		sim.WithStart(tue0800AM),
+		sim.WithEvents(
+			record(tue0800AM.Add(3*time.Minute), "third"),
+			record(tue0800AM.Add(time.Minute), "first"),
+			record(tue0800AM.Add(3*time.Minute), "fourth"),
+			record(tue0800AM.Add(2*time.Minute), "second"),
+			record(tue0800AM.Add(time.Hour), "never"),
+		),
+	)
+
+	s.Run(tue0800AM.Add(3 * time.Minute))
+	assert.Equal(t, []string{"first", "second", "third", "fourth"}, fired)
+	assert.Equal(t, tue0800AM.Add(3*time.Minute), s.Now())
+}
+
+func TestFireRecallEvacuatesPassengers(t *testing.T) {
+	b := newBuilding(t, 10)
+	upstairs, err := passenger.New(b, passenger.WithPrimaryFloor(9))
+	require.NoError(t, err)
+	atWork, err := passenger.New(b, passenger.WithPrimaryFloor(5), passenger.WithFloor(5), passenger.WithStatus(passenger.Active))
+	require.NoError(t, err)
+	passengers := []*passenger.Passenger{upstairs, atWork}
+
+	recall := tue0800AM.Add(4 * time.Minute)
+	s := newSim(t, b, passengers,
+		sim.WithStart(tue0800AM),
+		sim.WithEvents(
+			sim.FireRecall(recall, "main", false),
+			sim.FireRestore(recall.Add(30*time.Minute), "main"),
+		),
+	)
+
+	// the first passenger is on the way up when the alarm sounds
+	s.Run(recall.Add(-time.Minute))
+	assert.Equal(t, passenger.Riding, passengers[0].Status())
+
+	s.Run(recall.Add(15 * time.Minute))
+	z, _ := b.Zone("main")
+	assert.Equal(t, bank.FireRecall, z.Bank.Mode())
+	for _, p := range passengers {
+		assert.Equal(t, passenger.Evacuated, p.Status())
+		assert.Equal(t, 0, p.Floor())
+	}
+
+	// after the all clear everyone heads back to work
+	s.Run(recall.Add(time.Hour))
+	assert.Equal(t, bank.Normal, z.Bank.Mode())
+	assert.Equal(t, 9, passengers[0].Floor())
+	assert.Equal(t, 5, passengers[1].Floor())
+	for _, p := range passengers {
+		assert.Equal(t, passenger.Active, p.Status())
+	}
+}
+
+func TestEventForUnknownZoneIsSkipped(t *testing.T) {
+	s := newSim(t, newBuilding(t, 5), nil,
+		sim.WithStart(tue0800AM),
+		sim.WithEvents(sim.FireRecall(tue0800AM, "nowhere", false)),
+	)
+
+	s.Step()
+	assert.False(t, s.Building().InFireService())
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	b := newBuilding(t, 5)
+	s := newSim(t, b, nil,
+		sim.WithStart(tue0800AM),
+		sim.WithEvents(sim.MaintenanceWindow(tue0800AM.Add(time.Minute), tue0800AM.Add(time.Hour), "main", 1)...),
+	)
+	z, _ := b.Zone("main")
+
+	s.Run(tue0800AM.Add(30 * time.Minute))
+	assert.Equal(t, car.OutOfService, z.Bank.Car(1).Mode())
+
+	s.Run(tue0800AM.Add(time.Hour))
+	assert.Equal(t, car.Normal, z.Bank.Car(1).Mode())
+}
+
+func TestMaintenanceWindowOutlastsAFireDrill(t *testing.T) {
+	b := newBuilding(t, 5)
+	events := sim.MaintenanceWindow(tue0800AM.Add(time.Minute), tue0800AM.Add(time.Hour), "main", 1)
+	events = append(events,
+		sim.FireRecall(tue0800AM.Add(10*time.Minute), "main", false),
+		sim.FireRestore(tue0800AM.Add(20*time.Minute), "main"),
+		sim.FireRecall(tue0800AM.Add(50*time.Minute), "main", false),
+		sim.FireRestore(tue0800AM.Add(70*time.Minute), "main"),
+	)
+	s := newSim(t, b, nil, sim.WithStart(tue0800AM), sim.WithEvents(events...))
+	z, _ := b.Zone("main")
+
+	s.Run(tue0800AM.Add(30 * time.Minute))
+	assert.Equal(t, car.OutOfService, z.Bank.Car(1).Mode(), "still down for maintenance after the drill")
+	assert.Equal(t, car.Normal, z.Bank.Car(0).Mode())
+
+	s.Run(tue0800AM.Add(80 * time.Minute))
+	assert.Equal(t, car.Normal, z.Bank.Car(1).Mode(), "back once the window closed during the drill")
+}
+
+func TestReportWaits(t *testing.T) {
+	b := newBuilding(t, 10)
+	lobby, err := passenger.New(b, passenger.WithPrimaryFloor(3))
+	require.NoError(t, err)
+	upstairs, err := passenger.New(b, passenger.WithPrimaryFloor(5), passenger.WithFloor(9))
+	require.NoError(t, err)
+	passengers := []*passenger.Passenger{lobby, upstairs}
+	s := newSim(t, b, passengers, sim.WithStart(tue0800AM.Add(-time.Minute)))
+
+	s.Run(tue0800AM.Add(time.Hour))
+	r := s.Report()
+
+	// the lobby passenger waits a step for the doors to open, while the other waits for a car to climb to 9
+	assert.Equal(t, 2, r.Rides)
+	assert.Equal(t, 0, r.Abandoned)
+	assert.Equal(t, 0, r.Waiting)
+	assert.Equal(t, 9*time.Minute, r.MaxWait)
+	assert.Equal(t, 9*time.Minute, r.P90Wait)
+	assert.Equal(t, 5*time.Minute, r.MeanWait)
+
+	h := s.WaitHistogram()
+	assert.Equal(t, []int{1, 1, 1, 1, 2, 2, 2}, h.Buckets)
+	assert.Equal(t, r.Rides, h.Count)
+	assert.Equal(t, 10*time.Minute, h.Sum)
+	assert.Equal(t, r.Abandoned, s.Abandoned())
+	assert.Equal(t, r.Waiting, s.Waiting())
+}
+
+func TestFaultsAreDeterministicUnderSeed(t *testing.T) {
+	run := func(seed uint64) sim.Report {
+		b := newBuilding(t, 10)
+		passengers := []*passenger.Passenger{}
+		for floor := 1; floor < 10; floor++ {
+			p, err := passenger.New(b, passenger.WithPrimaryFloor(floor))
+			require.NoError(t, err)
+			passengers = append(passengers, p)
+		}
+		s := newSim(t, b, passengers,
+			sim.WithStart(tue0800AM),
+			sim.WithSeed(seed),
+			sim.WithFaults(sim.FaultModel{MTBF: 2 * time.Hour, MTTR: 30 * time.Minute}),
+		)
+		s.Run(tue0800AM.Add(10 * time.Hour))
+		return s.Report()
+	}
+
+	first := run(42)
+	assert.NotEmpty(t, first.Entrapments)
+	assert.Equal(t, first, run(42))
+	assert.NotEqual(t, first.Entrapments, run(7).Entrapments)
+}
+
+func TestFaultsTrapPassengersAndReduceAvailability(t *testing.T) {
+	b := newBuilding(t, 10)
+	p, err := passenger.New(b, passenger.WithPrimaryFloor(9))
+	require.NoError(t, err)
+	passengers := []*passenger.Passenger{p}
+	s := newSim(t, b, passengers, sim.WithStart(tue0800AM.Add(-time.Minute)))
+
+	// board, then fail both cars with the passenger partway up
+	s.Run(tue0800AM.Add(3 * time.Minute))
+	assert.Equal(t, passenger.Riding, passengers[0].Status())
+
+	withFaults := newSim(t, b, passengers,
+		sim.WithStart(s.Now()),
+		sim.WithFaults(sim.FaultModel{MTBF: time.Nanosecond, MTTR: time.Nanosecond, Faults: []car.Fault{car.Stall}}),
+	)
+	withFaults.Step()
+
+	r := withFaults.Report()
+	assert.Equal(t, 2, r.Breakdowns())
+	assert.Len(t, r.Entrapments, 1, "the empty car trapped no one")
+	assert.Equal(t, 1, r.TrappedPassengers())
+	for _, c := range r.Cars {
+		assert.Equal(t, 1, c.Failures)
+		assert.Equal(t, 0.0, c.Availability)
+		assert.Equal(t, time.Minute, c.Downtime)
+	}
+
+	// repairs come due on the next step, though with such a short MTBF the cars fail again straight away
+	withFaults.Step()
+	assert.Equal(t, withFaults.Now(), withFaults.Report().Entrapments[0].Released)
+}
+
+func TestWithFaultsErrors(t *testing.T) {
+	for _, model := range []sim.FaultModel{
+		{MTTR: time.Hour},
+		{MTBF: -time.Hour, MTTR: time.Hour},
+		{MTBF: time.Hour},
+		{MTBF: time.Hour, MTTR: -time.Minute},
+	} {
+		_, err := sim.New(newBuilding(t, 5), nil, sim.WithFaults(model))
+		assert.Error(t, err, "MTBF %v, MTTR %v", model.MTBF, model.MTTR)
+	}
+}
+
+func TestReportCountsPassengersWhoDoWithout(t *testing.T) {
+	b := newBuilding(t, 10)
+	walk := passenger.Patience{StairFloors: 1, StairChance: 1}
+	walker, err := passenger.New(b, passenger.WithPrimaryFloor(1), passenger.WithPatience(walk))
+	require.NoError(t, err)
+	impatient, err := passenger.New(b, passenger.WithPrimaryFloor(9), passenger.WithPatience(passenger.Patience{Mean: time.Nanosecond, Retry: time.Hour}))
+	require.NoError(t, err)
+	passengers := []*passenger.Passenger{walker, impatient}
+	s := newSim(t, b, passengers, sim.WithStart(tue0800AM.Add(-time.Minute)))
+
+	// send both cars up the building, so the second passenger gives up before one comes back
+	z, _ := b.Zone("main")
+	z.Bank.Car(0).Press(9)
+	z.Bank.Car(1).Press(9)
+	s.Run(tue0800AM.Add(2 * time.Minute))
+
+	r := s.Report()
+	assert.Equal(t, 1, r.Stairs)
+	assert.Equal(t, 1, r.Reneged)
+	assert.Equal(t, 1, r.Abandoned)
+	assert.Equal(t, 0, r.Rides)
+}
+
+func TestArrivalsBringPassengersWhoLeaveOnArrival(t *testing.T) {
+	b := newBuilding(t, 10)
+	profile := arrivals.Profile{
+		{Start: 0, Rate: 30, Incoming: 1, Outgoing: 1},
+		{Start: 10 * time.Hour, Rate: 0},
+	}
+	a, err := arrivals.New(profile, tue0800AM, 0, building.Span(1, 9), rand.New(rand.NewPCG(3, 4)))
+	assert.NoError(t, err)
+
+	s := newSim(t, b, nil, sim.WithStart(tue0800AM), sim.WithArrivals(a))
+	s.Run(tue0800AM.Add(time.Hour))
+	assert.NotEmpty(t, s.Passengers())
+
+	// arrivals stop at ten, and by noon everyone has finished their trip and left
+	s.Run(tue0800AM.Add(4 * time.Hour))
+	r := s.Report()
+	assert.Empty(t, s.Passengers())
+	assert.InDelta(t, 60, r.Rides, 20)
+	assert.Equal(t, 0, r.Waiting)
+}
+
+func TestCheckerReportsViolations(t *testing.T) {
+	b := newBuilding(t, 10)
+	p, err := passenger.New(b, passenger.WithPrimaryFloor(5), passenger.WithFloor(9))
+	require.NoError(t, err)
+	passengers := []*passenger.Passenger{p}
+	checker := invariant.New(invariant.WithHallCallBound(5 * time.Minute))
+	s := newSim(t, b, passengers, sim.WithStart(tue0800AM.Add(-time.Minute)), sim.WithChecker(checker))
+
+	s.Run(tue0800AM.Add(time.Hour))
+	r := s.Report()
+
+	// the climb to 9 takes longer than the bound allows
+	if assert.Len(t, r.Violations, 1) {
+		assert.Equal(t, invariant.HallCallUnanswered, r.Violations[0].Rule)
+		assert.Equal(t, 9, r.Violations[0].Floor)
+	}
+	var out strings.Builder
+	assert.NoError(t, r.Print(&out))
+	assert.Contains(t, out.String(), "invariant violations: 1")
+}
@@ -0,0 +1,201 @@
+// Package tower builds the office tower that simuvator simulates: sixteen floors above two basements of
+// parking, served by a low-rise bank, an express shuttle to the sky lobby on 9 and a high-rise bank above it.
+package tower
+
+import (
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+	"github.com/dshaneg/elevator/internal/building"
+	"github.com/dshaneg/elevator/internal/elevator/bank"
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/floorplan"
+	"github.com/dshaneg/elevator/internal/invariant"
+	"github.com/dshaneg/elevator/internal/passenger"
+	"github.com/dshaneg/elevator/internal/scenario"
+	"github.com/dshaneg/elevator/internal/sim"
+	"github.com/dshaneg/elevator/internal/trace"
+)
+
+// Start is when a Simulation of the tower begins: a Monday morning before the rush.
+var Start = time.Date(2024, 11, 18, 7, 0, 0, 0, time.Local)
+
+// Config holds the settings of a Simulation of the tower.
+type Config struct {
+	Seed         uint64             // seeds the random numbers drawn by the Simulation
+	Visitors     *scenario.Scenario // the traffic of visitors making one-off trips if it has any, and the day's events; nil for none
+	Trace        trace.Trace        // logged calls replayed as more visitors' trips, or nil for none
+	Regenerative bool               // fits every car with a regenerative drive
+	EnergyWeight float64            // seconds of waiting charged per watt hour when dispatching
+	Faults       *sim.FaultModel    // breaks cars down at random, or nil for none
+	Actors       bool               // runs every car as its own goroutine; see bank.WithActors
+	Twins        bool               // runs the low- and high-rise banks as two shafts of twin cars each, in place of three cars
+	Network      *bank.Network      // the field bus each bank dispatches its cars over, or nil to dispatch directly
+	Checker      *invariant.Checker // checks the invariants of the tower every step, or nil for none
+	Logger       *slog.Logger       // where dispatch decisions and events are logged; nil discards them
+}
+
+// New builds a Simulation of the tower. Two people work on every floor above the lobby and one in three
+// of them drives in. Nothing is scheduled to happen to the cars but what the Visitors' scenario lays out
+// in its timeline.
+func New(cfg Config) (*sim.Simulation, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	energy := car.DefaultEnergyModel
+	energy.Regenerative = cfg.Regenerative
+
+	plan, err := floorplan.New(2, 16, floorplan.WithoutThirteen(), floorplan.WithParking(2))
+	if err != nil {
+		return nil, err
+	}
+
+	skyLobby, _ := plan.Index("9")
+	basement, _ := plan.Index("B1")
+	highRise, _ := plan.Index("10")
+	garage, _ := plan.Index("B2")
+
+	bankOptions := []bank.Option{bank.WithEnergyWeight(cfg.EnergyWeight), bank.WithLogger(logger)}
+	if cfg.Actors {
+		bankOptions = append(bankOptions, bank.WithActors())
+	}
+	if cfg.Network != nil {
+		bankOptions = append(bankOptions, bank.WithNetwork(*cfg.Network, rand.New(rand.NewPCG(cfg.Seed, 2))))
+	}
+
+	var lowRise, high *bank.Bank
+	// twin shafts run a floor past the end of their zone, where a car can wait out of its twin's way,
+	// so that both twins reach the lobby
+	if cfg.Twins {
+		lowRise, err = newTwinBank(plan, 2, garage, skyLobby, plan.Lobby(), basement, energy, bankOptions...)
+	} else {
+		lowRise, err = newBank(plan, 3, plan.Lobby(), basement, energy, bankOptions...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	shuttle, err := newBank(plan, 2, plan.Lobby(), skyLobby, energy, bankOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Twins {
+		high, err = newTwinBank(plan, 2, skyLobby-1, plan.Len()-1, skyLobby, highRise, energy, bankOptions...)
+	} else {
+		high, err = newBank(plan, 3, skyLobby, highRise, energy, bankOptions...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := building.New(plan,
+		building.Zone{Name: "low-rise", Bank: lowRise, Floors: building.Span(0, skyLobby-1)},
+		building.Zone{Name: "shuttle", Bank: shuttle, Floors: []int{plan.Lobby(), skyLobby}},
+		building.Zone{Name: "high-rise", Bank: high, Floors: building.Span(skyLobby, plan.Len()-1)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	passengers := []*passenger.Passenger{}
+	r := rand.New(rand.NewPCG(cfg.Seed, 1))
+	for office := plan.Lobby() + 1; office < plan.Len(); office++ {
+		for range 2 {
+			options := []passenger.Option{
+				passenger.WithPrimaryFloor(office),
+				passenger.WithPatience(passenger.DefaultPatience),
+				passenger.WithRand(r),
+			}
+			if len(passengers)%3 == 0 {
+				options = append(options, passenger.WithHomeFloor(garage))
+			}
+			p, err := passenger.New(b, options...)
+			if err != nil {
+				return nil, err
+			}
+			passengers = append(passengers, p)
+		}
+	}
+
+	var events []sim.Event
+	if cfg.Visitors != nil {
+		if events, err = cfg.Visitors.Timeline(b, Start); err != nil {
+			return nil, err
+		}
+	}
+
+	options := []sim.Option{
+		sim.WithStart(Start),
+		sim.WithLogger(logger),
+		sim.WithEvents(events...),
+		sim.WithSeed(cfg.Seed),
+	}
+	if cfg.Visitors != nil && cfg.Visitors.HasTraffic() {
+		profile, err := cfg.Visitors.Profile()
+		if err != nil {
+			return nil, err
+		}
+		// visitors come and go through the main lobby to any floor above it
+		a, err := arrivals.New(profile, Start, plan.Lobby(), building.Span(plan.Lobby()+1, plan.Len()-1), r)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sim.WithArrivals(a, passenger.WithPatience(passenger.DefaultPatience), passenger.WithRand(r)))
+	}
+	if cfg.Trace != nil {
+		replay, err := cfg.Trace.Replay(plan, Start)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sim.WithArrivals(replay, passenger.WithPatience(passenger.DefaultPatience), passenger.WithRand(r)))
+	}
+	if cfg.Faults != nil {
+		options = append(options, sim.WithFaults(*cfg.Faults))
+	}
+	if cfg.Checker != nil {
+		options = append(options, sim.WithChecker(cfg.Checker))
+	}
+
+	return sim.New(b, passengers, options...)
+}
+
+// newBank creates a bank whose cars wait at its lobby, which is also where they are recalled to in a fire.
+func newBank(plan *floorplan.Plan, carCount int, lobby, alternate int, energy car.EnergyModel, options ...bank.Option) (*bank.Bank, error) {
+	cars := []bank.Member{}
+	for range carCount {
+		c, err := car.NewCar(plan.Len(), car.WithFloor(lobby), car.WithEnergyModel(energy))
+		if err != nil {
+			return nil, err
+		}
+		cars = append(cars, c)
+	}
+
+	return bank.New(plan.Len(), cars, append([]bank.Option{bank.WithRecallFloors(lobby, alternate)}, options...)...)
+}
+
+// newTwinBank creates a bank of twin cars, two to each shaft running from the lowest floor to the highest,
+// a floor apart. The lower cars wait at the bank's lobby and the upper cars at the top of their shafts.
+func newTwinBank(plan *floorplan.Plan, shafts, lowest, highest, lobby, alternate int, energy car.EnergyModel, options ...bank.Option) (*bank.Bank, error) {
+	cars := []bank.Member{}
+	for range shafts {
+		s, err := car.NewShaft(lowest, highest, 1)
+		if err != nil {
+			return nil, err
+		}
+		lower, err := car.NewCar(plan.Len(), car.WithShaft(s, car.LowerTwin), car.WithFloor(lobby), car.WithEnergyModel(energy))
+		if err != nil {
+			return nil, err
+		}
+		upper, err := car.NewCar(plan.Len(), car.WithShaft(s, car.UpperTwin), car.WithFloor(highest), car.WithEnergyModel(energy))
+		if err != nil {
+			return nil, err
+		}
+		cars = append(cars, lower, upper)
+	}
+
+	return bank.New(plan.Len(), cars, append([]bank.Option{bank.WithRecallFloors(lobby, alternate)}, options...)...)
+}
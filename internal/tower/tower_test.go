@@ -0,0 +1,137 @@
+package tower_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/elevator/car"
+	"github.com/dshaneg/elevator/internal/invariant"
+	"github.com/dshaneg/elevator/internal/passenger"
+	"github.com/dshaneg/elevator/internal/scenario"
+	"github.com/dshaneg/elevator/internal/tower"
+	"github.com/dshaneg/elevator/internal/trace"
+)
+
+func TestNew(t *testing.T) {
+	s, err := tower.New(tower.Config{Seed: 1, Visitors: &scenario.Scenario{Traffic: "office"}})
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, z := range s.Building().Zones() {
+		names = append(names, z.Name)
+	}
+	assert.Equal(t, []string{"low-rise", "shuttle", "high-rise"}, names)
+	assert.Equal(t, tower.Start, s.Now())
+	assert.Len(t, s.Passengers(), 32) // two on each of the sixteen floors above the lobby
+}
+
+func TestNewSchedulesNothingOfItsOwn(t *testing.T) {
+	s, err := tower.New(tower.Config{Seed: 1})
+	require.NoError(t, err)
+
+	for hour := range 10 {
+		s.Run(tower.Start.Add(time.Duration(hour) * time.Hour))
+		for _, z := range s.Building().Zones() {
+			for i := range z.Bank.NumCars() {
+				assert.Equal(t, car.Normal, z.Bank.Car(i).Mode(), "%s car %d at %s", z.Name, i, s.Now().Format("15:04"))
+			}
+		}
+	}
+}
+
+func TestNewRejectsABadScenario(t *testing.T) {
+	_, err := tower.New(tower.Config{Visitors: &scenario.Scenario{Traffic: "stadium"}})
+
+	assert.Error(t, err)
+}
+
+func TestNewFollowsTheScenarioTimeline(t *testing.T) {
+	visitors := &scenario.Scenario{Traffic: "office", Events: []scenario.Event{
+		{Kind: "maintenance", At: "07:30", Until: "08:00", Zone: "high-rise", Car: 2},
+	}}
+	s, err := tower.New(tower.Config{Seed: 1, Visitors: visitors})
+	require.NoError(t, err)
+	highRise, _ := s.Building().Zone("high-rise")
+
+	s.Run(tower.Start.Add(50 * time.Minute))
+	assert.Equal(t, car.OutOfService, highRise.Bank.Car(2).Mode())
+
+	s.Run(tower.Start.Add(61 * time.Minute))
+	assert.Equal(t, car.Normal, highRise.Bank.Car(2).Mode())
+
+	visitors.Events[0].Zone = "penthouse"
+	_, err = tower.New(tower.Config{Visitors: visitors})
+	assert.Error(t, err)
+}
+
+func TestNewWithOnlyATimeline(t *testing.T) {
+	visitors := &scenario.Scenario{Events: []scenario.Event{
+		{Kind: "maintenance", At: "07:30", Until: "08:00", Zone: "low-rise", Car: 1},
+	}}
+	s, err := tower.New(tower.Config{Seed: 1, Visitors: visitors})
+	require.NoError(t, err)
+	lowRise, _ := s.Building().Zone("low-rise")
+
+	s.Run(tower.Start.Add(45 * time.Minute))
+	assert.Equal(t, car.OutOfService, lowRise.Bank.Car(1).Mode())
+	assert.Len(t, s.Passengers(), 32, "no visitors arrive")
+}
+
+func TestNewReplaysATrace(t *testing.T) {
+	at := time.Date(2023, 3, 6, 7, 5, 0, 0, time.UTC)
+	s, err := tower.New(tower.Config{Trace: trace.Trace{{At: at, Floor: "G", Destination: "12", Passengers: 3}}})
+	assert.NoError(t, err)
+
+	s.Run(tower.Start.Add(5 * time.Minute))
+	assert.Len(t, s.Passengers(), 35)
+
+	_, err = tower.New(tower.Config{Trace: trace.Trace{{At: at, Floor: "G", Destination: "13"}}})
+	assert.Error(t, err, "there is no thirteenth floor")
+}
+
+func TestActorsMakeNoDifference(t *testing.T) {
+	cfg := tower.Config{Seed: 3, Visitors: &scenario.Scenario{Traffic: "office", Scale: 0.5}}
+	plain, err := tower.New(cfg)
+	require.NoError(t, err)
+
+	cfg.Actors = true
+	actors, err := tower.New(cfg)
+	require.NoError(t, err)
+	defer actors.Close()
+
+	until := tower.Start.Add(3 * time.Hour)
+	plain.Run(until)
+	actors.Run(until)
+
+	assert.Equal(t, plain.Report(), actors.Report())
+}
+
+func TestNewWithTwins(t *testing.T) {
+	s, err := tower.New(tower.Config{Seed: 1, Twins: true})
+	require.NoError(t, err)
+
+	for _, name := range []string{"low-rise", "high-rise"} {
+		z, _ := s.Building().Zone(name)
+		assert.Equal(t, 4, z.Bank.NumCars(), name)
+	}
+
+	// everyone gets to their desk by late morning, whichever twin they need, though some give up on the
+	// shuttle now and then and try again later
+	s.Run(tower.Start.Add(4 * time.Hour))
+	for _, p := range s.Passengers() {
+		assert.Equal(t, passenger.Active, p.Status())
+	}
+}
+
+func TestNewKeepsItsInvariants(t *testing.T) {
+	for _, twins := range []bool{false, true} {
+		s, err := tower.New(tower.Config{Seed: 1, Twins: twins, Checker: invariant.New()})
+		require.NoError(t, err)
+
+		s.Run(tower.Start.Add(6 * time.Hour))
+		assert.Empty(t, s.Report().Violations, "twins: %v", twins)
+	}
+}
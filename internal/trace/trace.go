@@ -0,0 +1,247 @@
+// Package trace replays the call logs of real elevator controllers as arrivals, so that dispatch changes can be
+// tried against real demand rather than synthetic schedules.
+//
+// A trace is a list of timestamped calls, each made at a landing by one or more people going to the same
+// destination. It is read from CSV with a header row, whose columns may come in any order:
+//
+//	time,floor,direction,destination,passengers
+//	2024-11-18T08:01:12-06:00,G,up,12,2
+//	2024-11-18 08:01:40,7,,G,
+//
+// or from a JSON array:
+//
+//	[
+//		{"time": "2024-11-18T08:01:12-06:00", "floor": "G", "direction": "up", "destination": "12", "passengers": 2},
+//		{"time": "2024-11-18 08:01:40", "floor": "7", "destination": "G"}
+//	]
+//
+// Times are RFC 3339, or a date and time of day without a zone. Floors are given by their labels in the
+// Building's floor plan. The direction may be left out, as it follows from the destination, and the
+// number of passengers defaults to one.
+package trace
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+	"github.com/dshaneg/elevator/internal/floorplan"
+)
+
+// layouts are the accepted forms of a call's time.
+var layouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+// Call is a call made at a landing, as logged by a controller.
+type Call struct {
+	At          time.Time
+	Floor       string // the label of the floor the call was made at
+	Direction   string // "up" or "down", or empty to follow from the destination
+	Destination string // the label of the floor the passengers were going to
+	Passengers  int    // how many people made the call; 0 is taken as 1
+}
+
+// Trace is a log of Calls.
+type Trace []Call
+
+// ReadCSV reads a Trace from CSV with a header row naming its columns.
+func ReadCSV(r io.Reader) (Trace, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("trace: reading the header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "time", "floor", "direction", "destination", "passengers":
+		default:
+			return nil, fmt.Errorf("trace: unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"time", "floor", "destination"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("trace: no %s column", name)
+		}
+	}
+
+	var t Trace
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("trace: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		c := Call{Floor: field("floor"), Direction: field("direction"), Destination: field("destination")}
+		if c.At, err = parseTime(field("time")); err != nil {
+			return nil, fmt.Errorf("trace: line %d: %w", line, err)
+		}
+		if n := field("passengers"); n != "" {
+			if c.Passengers, err = strconv.Atoi(n); err != nil {
+				return nil, fmt.Errorf("trace: line %d: passengers %q is not a number", line, n)
+			}
+		}
+		t = append(t, c)
+	}
+}
+
+// ReadJSON reads a Trace from a JSON array of calls.
+func ReadJSON(r io.Reader) (Trace, error) {
+	var calls []struct {
+		Time        string `json:"time"`
+		Floor       string `json:"floor"`
+		Direction   string `json:"direction"`
+		Destination string `json:"destination"`
+		Passengers  int    `json:"passengers"`
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&calls); err != nil {
+		return nil, fmt.Errorf("trace: %w", err)
+	}
+
+	t := make(Trace, 0, len(calls))
+	for i, c := range calls {
+		at, err := parseTime(c.Time)
+		if err != nil {
+			return nil, fmt.Errorf("trace: call %d: %w", i, err)
+		}
+		t = append(t, Call{At: at, Floor: c.Floor, Direction: c.Direction, Destination: c.Destination, Passengers: c.Passengers})
+	}
+	return t, nil
+}
+
+// LoadFile reads a Trace from the file at the given path, as CSV or JSON by its extension.
+func LoadFile(path string) (Trace, error) {
+	var read func(io.Reader) (Trace, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		read = ReadCSV
+	case ".json":
+		read = ReadJSON
+	default:
+		return nil, fmt.Errorf("trace: %s is neither .csv nor .json", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("trace: %w", err)
+	}
+	defer f.Close()
+
+	return read(f)
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range layouts {
+		if at, err := time.Parse(layout, s); err == nil {
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("time %q is neither RFC 3339 nor like 2006-01-02 15:04:05", s)
+}
+
+// Replay is an arrivals.Source bringing the Trips of a Trace, one for each of a Call's passengers.
+type Replay struct {
+	trips []arrivals.Trip // in order of arrival
+}
+
+// Replay checks the Calls of the Trace against the floor plan and returns them as Trips beginning at start.
+//
+// The Trace is moved onto the day the Simulation starts, keeping the time of day of each Call on the
+// clock on the wall, so that a log from any day can be replayed; a log of several days stays several days.
+// Calls from before the start are dropped.
+func (t Trace) Replay(plan *floorplan.Plan, start time.Time) (*Replay, error) {
+	if len(t) == 0 {
+		return nil, errors.New("trace: no calls")
+	}
+	first := slices.MinFunc(t, func(a, b Call) int { return a.At.Compare(b.At) })
+	firstDay := day(first.At)
+
+	r := Replay{}
+	for i, c := range t {
+		from, err := plan.Index(c.Floor)
+		if err != nil {
+			return nil, fmt.Errorf("trace: call %d: %w", i, err)
+		}
+		to, err := plan.Index(c.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("trace: call %d: %w", i, err)
+		}
+		if from == to {
+			return nil, fmt.Errorf("trace: call %d: %s is both the floor and the destination", i, c.Floor)
+		}
+		switch strings.ToLower(c.Direction) {
+		case "":
+		case "up":
+			if to < from {
+				return nil, fmt.Errorf("trace: call %d: %s is not up from %s", i, c.Destination, c.Floor)
+			}
+		case "down":
+			if to > from {
+				return nil, fmt.Errorf("trace: call %d: %s is not down from %s", i, c.Destination, c.Floor)
+			}
+		default:
+			return nil, fmt.Errorf("trace: call %d: direction %q is neither up nor down", i, c.Direction)
+		}
+		if c.Passengers < 0 {
+			return nil, fmt.Errorf("trace: call %d: %d passengers", i, c.Passengers)
+		}
+
+		days := int(day(c.At).Sub(firstDay).Hours() / 24)
+		at := time.Date(start.Year(), start.Month(), start.Day()+days,
+			c.At.Hour(), c.At.Minute(), c.At.Second(), c.At.Nanosecond(), start.Location())
+		if at.Before(start) {
+			continue
+		}
+		for range max(c.Passengers, 1) {
+			r.trips = append(r.trips, arrivals.Trip{At: at, From: from, To: to})
+		}
+	}
+
+	slices.SortStableFunc(r.trips, func(a, b arrivals.Trip) int { return a.At.Compare(b.At) })
+	return &r, nil
+}
+
+// day returns the midnight, in UTC, of the date on the wall clock at the given time.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Until returns the Trips that arrive up to and including the given time, in order.
+func (r *Replay) Until(t time.Time) []arrivals.Trip {
+	i := 0
+	for i < len(r.trips) && !r.trips[i].At.After(t) {
+		i++
+	}
+	trips := r.trips[:i:i]
+	r.trips = r.trips[i:]
+	return trips
+}
+
+// Len returns how many Trips are still to come.
+func (r *Replay) Len() int {
+	return len(r.trips)
+}
@@ -0,0 +1,138 @@
+package trace_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dshaneg/elevator/internal/arrivals"
+	"github.com/dshaneg/elevator/internal/floorplan"
+	"github.com/dshaneg/elevator/internal/trace"
+)
+
+// mon0700AM is the start of a working week.
+var mon0700AM = time.Date(2024, 11, 18, 7, 0, 0, 0, time.UTC)
+
+func newPlan(t *testing.T) *floorplan.Plan {
+	plan, err := floorplan.New(1, 5)
+	require.NoError(t, err)
+	return plan
+}
+
+func TestReadCSV(t *testing.T) {
+	tr, err := trace.ReadCSV(strings.NewReader(`destination, floor, time, passengers, direction
+3, G, 2023-03-06T08:01:12-06:00, 2, up
+G, 4, 2023-03-06 08:01:40, ,
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, trace.Trace{
+		{At: time.Date(2023, 3, 6, 8, 1, 12, 0, time.FixedZone("", -6*60*60)), Floor: "G", Direction: "up", Destination: "3", Passengers: 2},
+		{At: time.Date(2023, 3, 6, 8, 1, 40, 0, time.UTC), Floor: "4", Destination: "G"},
+	}, tr)
+}
+
+func TestReadJSON(t *testing.T) {
+	tr, err := trace.ReadJSON(strings.NewReader(`[
+		{"time": "2023-03-06T08:01:12Z", "floor": "G", "direction": "up", "destination": "3", "passengers": 2},
+		{"time": "2023-03-06 08:01:40", "floor": "4", "destination": "G"}
+	]`))
+	require.NoError(t, err)
+
+	assert.Equal(t, trace.Trace{
+		{At: time.Date(2023, 3, 6, 8, 1, 12, 0, time.UTC), Floor: "G", Direction: "up", Destination: "3", Passengers: 2},
+		{At: time.Date(2023, 3, 6, 8, 1, 40, 0, time.UTC), Floor: "4", Destination: "G"},
+	}, tr)
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(string) (trace.Trace, error)
+		data string
+	}{
+		{name: "Unknown column", read: csv, data: "time,floor,destination,weight\n"},
+		{name: "Missing column", read: csv, data: "time,floor\n"},
+		{name: "Bad time", read: csv, data: "time,floor,destination\n8am,G,3\n"},
+		{name: "Bad passengers", read: csv, data: "time,floor,destination,passengers\n2023-03-06 08:00:00,G,3,two\n"},
+		{name: "Unknown field", read: js, data: `[{"time": "2023-03-06 08:00:00", "floor": "G", "destination": "3", "weight": 80}]`},
+		{name: "Bad JSON time", read: js, data: `[{"time": "08:00", "floor": "G", "destination": "3"}]`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.read(tc.data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func csv(data string) (trace.Trace, error) { return trace.ReadCSV(strings.NewReader(data)) }
+func js(data string) (trace.Trace, error)  { return trace.ReadJSON(strings.NewReader(data)) }
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "calls.csv")
+	require.NoError(t, os.WriteFile(path, []byte("time,floor,destination\n2023-03-06 08:00:00,G,3\n"), 0o600))
+
+	tr, err := trace.LoadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, tr, 1)
+
+	_, err = trace.LoadFile(filepath.Join(dir, "calls.txt"))
+	assert.Error(t, err)
+}
+
+func TestReplay(t *testing.T) {
+	tr := trace.Trace{
+		{At: time.Date(2023, 3, 7, 7, 30, 0, 0, time.UTC), Floor: "3", Destination: "G"}, // the next day
+		{At: time.Date(2023, 3, 6, 8, 15, 0, 0, time.UTC), Floor: "G", Direction: "up", Destination: "4", Passengers: 2},
+		{At: time.Date(2023, 3, 6, 6, 55, 0, 0, time.UTC), Floor: "G", Destination: "1"}, // before the start
+	}
+
+	replay, err := tr.Replay(newPlan(t), mon0700AM)
+	require.NoError(t, err)
+	assert.Equal(t, 3, replay.Len())
+
+	assert.Empty(t, replay.Until(mon0700AM.Add(time.Hour)))
+	assert.Equal(t, []arrivals.Trip{
+		{At: mon0700AM.Add(75 * time.Minute), From: 1, To: 5},
+		{At: mon0700AM.Add(75 * time.Minute), From: 1, To: 5},
+	}, replay.Until(mon0700AM.Add(75*time.Minute)))
+	assert.Empty(t, replay.Until(mon0700AM.Add(2*time.Hour)), "each trip arrives only once")
+
+	assert.Equal(t, []arrivals.Trip{
+		{At: mon0700AM.Add(24*time.Hour + 30*time.Minute), From: 4, To: 1},
+	}, replay.Until(mon0700AM.AddDate(0, 0, 2)))
+	assert.Zero(t, replay.Len())
+}
+
+func TestReplayErrors(t *testing.T) {
+	at := time.Date(2023, 3, 6, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		call trace.Call
+	}{
+		{name: "Unknown floor", call: trace.Call{At: at, Floor: "9", Destination: "G"}},
+		{name: "Unknown destination", call: trace.Call{At: at, Floor: "G", Destination: "P1"}},
+		{name: "Going nowhere", call: trace.Call{At: at, Floor: "3", Destination: "3"}},
+		{name: "Wrong direction", call: trace.Call{At: at, Floor: "3", Direction: "up", Destination: "G"}},
+		{name: "Unknown direction", call: trace.Call{At: at, Floor: "3", Direction: "sideways", Destination: "G"}},
+		{name: "Negative passengers", call: trace.Call{At: at, Floor: "3", Destination: "G", Passengers: -1}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := trace.Trace{tc.call}.Replay(newPlan(t), mon0700AM)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := trace.Trace{}.Replay(newPlan(t), mon0700AM)
+	assert.Error(t, err)
+}